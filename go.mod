@@ -2,6 +2,14 @@ module main
 
 go 1.23.5
 
-require golang.org/x/term v0.32.0
+require (
+	github.com/zalando/go-keyring v0.2.8
+	golang.org/x/crypto v0.38.0
+	golang.org/x/term v0.32.0
+)
 
-require golang.org/x/sys v0.33.0 // indirect
+require (
+	github.com/danieljoos/wincred v1.2.3 // indirect
+	github.com/godbus/dbus/v5 v5.2.2 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+)
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+)
+
+// aliasesFile maps local nicknames to friends' user IDs
+const aliasesFile = "aliases.json"
+
+// loadAliases returns the saved aliases, keyed by lowercase alias; a missing file is empty
+func loadAliases() (map[string]string, error) {
+	aliases := make(map[string]string)
+	data, err := readStateFile(aliasesFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return aliases, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", aliasesFile, err)
+	}
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", aliasesFile, err)
+	}
+	return aliases, nil
+}
+
+// saveAliases writes the aliases to aliases.json
+func saveAliases(aliases map[string]string) error {
+	jsonData, err := json.MarshalIndent(aliases, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %v", aliasesFile, err)
+	}
+	return writeStateFile(aliasesFile, jsonData)
+}
+
+// friendByAlias returns the friend that name is an alias for, or nil when name is
+// not an alias of anyone in friends. Aliases are matched case-insensitively.
+func friendByAlias(friends *FriendsData, name string) *Friend {
+	aliases, err := loadAliases()
+	if err != nil {
+		return nil
+	}
+	userID, ok := aliases[strings.ToLower(name)]
+	if !ok {
+		return nil
+	}
+	for i := range friends.Friends {
+		if friends.Friends[i].GetUserID() == userID {
+			return &friends.Friends[i]
+		}
+	}
+	return nil
+}
+
+// friendDisplayName returns the friend's username followed by their aliases in
+// parentheses, such as "jdoe_1984 (mom)"
+func friendDisplayName(friend *Friend) string {
+	aliases, err := loadAliases()
+	if err != nil {
+		return friend.GetUsername()
+	}
+
+	var names []string
+	for alias, userID := range aliases {
+		if userID == friend.GetUserID() {
+			names = append(names, alias)
+		}
+	}
+	if len(names) == 0 {
+		return friend.GetUsername()
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("%s (%s)", friend.GetUsername(), strings.Join(names, ", "))
+}
+
+// manageAliases implements the alias command: alias [list], alias add <name> <username>
+// and alias rm <name>
+func manageAliases() error {
+	var args []string
+	for _, arg := range os.Args[2:] {
+		if !strings.HasPrefix(arg, "--") {
+			args = append(args, arg)
+		}
+	}
+	if len(args) == 0 || args[0] == "list" {
+		return listAliases()
+	}
+
+	switch {
+	case args[0] == "add" && len(args) == 3:
+		return addAlias(args[1], args[2])
+	case args[0] == "rm" && len(args) == 2:
+		return removeAlias(args[1])
+	}
+	return fmt.Errorf("usage: go run main.go alias [list] | alias add <name> <username> | alias rm <name>")
+}
+
+// addAlias saves name as an alias for the friend with the given username
+func addAlias(name, username string) error {
+	if strings.ContainsFunc(name, func(r rune) bool { return r == ' ' || r == '\t' }) {
+		return fmt.Errorf("alias %q must not contain spaces", name)
+	}
+
+	token, err := requireToken()
+	if err != nil {
+		return err
+	}
+	friends, err := loadFriends(token.Token, hasFlag(os.Args[2:], "--offline"))
+	if err != nil {
+		return fmt.Errorf("error fetching friends: %v", err)
+	}
+
+	var friend *Friend
+	for i := range friends.Friends {
+		if strings.EqualFold(friends.Friends[i].GetUsername(), username) {
+			friend = &friends.Friends[i]
+			break
+		}
+	}
+	if friend == nil {
+		return fmt.Errorf("no friend named %q in your friends list", username)
+	}
+
+	aliases, err := loadAliases()
+	if err != nil {
+		return err
+	}
+	aliases[strings.ToLower(name)] = friend.GetUserID()
+	if err := saveAliases(aliases); err != nil {
+		return err
+	}
+
+	fmt.Printf("Alias %q now refers to %s.\n", strings.ToLower(name), friend.GetUsername())
+	return nil
+}
+
+// removeAlias deletes the alias name
+func removeAlias(name string) error {
+	aliases, err := loadAliases()
+	if err != nil {
+		return err
+	}
+	if _, ok := aliases[strings.ToLower(name)]; !ok {
+		return fmt.Errorf("no alias named %q", name)
+	}
+
+	delete(aliases, strings.ToLower(name))
+	if err := saveAliases(aliases); err != nil {
+		return err
+	}
+
+	fmt.Printf("Alias %q removed.\n", strings.ToLower(name))
+	return nil
+}
+
+// listAliases prints the saved aliases with the user IDs they refer to
+func listAliases() error {
+	aliases, err := loadAliases()
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		return printJSON(aliases)
+	}
+	if len(aliases) == 0 {
+		fmt.Println("No aliases yet, use `alias add <name> <username>` to add one.")
+		return nil
+	}
+
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("%-16s %s\n", "Alias", "User ID")
+	fmt.Println(strings.Repeat("-", 56))
+	for _, name := range names {
+		fmt.Printf("%-16s %s\n", name, aliases[name])
+	}
+	return nil
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// APIClient talks to the chat backend on behalf of one logged-in user.
+// Commands build one from the loaded token instead of assembling requests inline,
+// and tests can point it at another server by changing BaseURL and HTTPClient.
+// Requests are built with Context, so cancelling it aborts them.
+type APIClient struct {
+	HTTPClient *http.Client
+	BaseURL    string
+	Token      string
+	Context    context.Context
+}
+
+// NewAPIClient returns a client for the configured backend authenticated with token
+func NewAPIClient(token string) *APIClient {
+	return &APIClient{
+		HTTPClient: newHTTPClient(),
+		BaseURL:    apiBaseURL(),
+		Token:      token,
+		Context:    appContext(),
+	}
+}
+
+// newRequest builds an authenticated request for path, encoding payload as JSON when it is not nil
+func (c *APIClient) newRequest(method, path string, payload any) (*http.Request, error) {
+	var body io.Reader
+	if payload != nil {
+		jsonData, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %v", err)
+		}
+		body = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(c.Context, method, c.BaseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// do sends req with retries and returns the response body, or an *APIError
+// when the status is not a success
+func (c *APIClient) do(req *http.Request) ([]byte, error) {
+	resp, err := doRequestWithRetry(c.HTTPClient, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	if !isSuccess(resp.StatusCode) {
+		return nil, newAPIError(resp, body)
+	}
+	return body, nil
+}
+
+// SendMessage sends message to the user with recipientID. The returned response
+// is nil when the message was accepted but the reply could not be parsed.
+func (c *APIClient) SendMessage(message, recipientID string) (*MessageResponse, error) {
+	return c.SendMessageRequest(MessageRequest{Message: message, RecipientUserID: recipientID})
+}
+
+// SendMessageRequest sends a message that may carry an attachment. Like SendMessage
+// the returned response is nil when the reply could not be parsed.
+func (c *APIClient) SendMessageRequest(request MessageRequest) (*MessageResponse, error) {
+	req, err := c.newRequest("POST", "/auth/send_message", request)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var messageResp MessageResponse
+	if err := json.Unmarshal(body, &messageResp); err != nil {
+		return nil, nil
+	}
+	return &messageResp, nil
+}
+
+// GetConversation fetches the conversation with the user friendUserID
+func (c *APIClient) GetConversation(friendUserID string) (*ConversationResponse, error) {
+	return c.GetConversationPage(friendUserID, ConversationQuery{})
+}
+
+// ConversationQuery selects part of a conversation. Zero values mean no limit.
+type ConversationQuery struct {
+	// Limit is the maximum number of messages, the most recent ones
+	Limit int
+	// Before only includes messages with a lower message ID
+	Before int
+}
+
+// values returns the query as URL parameters
+func (q ConversationQuery) values() url.Values {
+	params := url.Values{}
+	if q.Limit > 0 {
+		params.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.Before > 0 {
+		params.Set("before", strconv.Itoa(q.Before))
+	}
+	return params
+}
+
+// apply narrows the messages to the query on the client side, for backends that
+// ignore the parameters. Messages the backend already filtered are left as they are.
+func (q ConversationQuery) apply(conversation *ConversationResponse) {
+	messages := conversation.Conversation
+	if q.Before > 0 {
+		var earlier []Message
+		for _, msg := range messages {
+			if msg.MessageID < q.Before {
+				earlier = append(earlier, msg)
+			}
+		}
+		messages = earlier
+	}
+	if q.Limit > 0 && len(messages) > q.Limit {
+		messages = messages[len(messages)-q.Limit:]
+	}
+	conversation.Conversation = messages
+}
+
+// GetConversationPage fetches the part of the conversation with the user with
+// friendUserID selected by query
+func (c *APIClient) GetConversationPage(friendUserID string, query ConversationQuery) (*ConversationResponse, error) {
+	path := "/auth/conversation/" + friendUserID
+	if params := query.values(); len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+
+	req, err := c.newRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var conversation ConversationResponse
+	if err := json.Unmarshal(body, &conversation); err != nil {
+		return nil, parseError(err, body)
+	}
+	query.apply(&conversation)
+	return &conversation, nil
+}
+
+// GetFriendsResponse fetches the friends list as returned by the API, normalizing every entry
+func (c *APIClient) GetFriendsResponse() (*FriendsAPIResponse, error) {
+	req, err := c.newRequest("GET", "/auth/get_friends", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse FriendsAPIResponse
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+	for i := range apiResponse.Friends {
+		NormalizeFriend(&apiResponse.Friends[i])
+	}
+	return &apiResponse, nil
+}
+
+// GetFriends fetches the friends list
+func (c *APIClient) GetFriends() (*FriendsData, error) {
+	apiResponse, err := c.GetFriendsResponse()
+	if err != nil {
+		return nil, err
+	}
+	return &FriendsData{Friends: apiResponse.Friends}, nil
+}
+
+// SearchUser looks up a user by username
+func (c *APIClient) SearchUser(username string) (*APIResponse, error) {
+	req, err := c.newRequest("GET", "/auth/search_user", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("username", username)
+
+	body, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse APIResponse
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode API response: %w", err)
+	}
+	return &apiResponse, nil
+}
+
+// DeleteMessage deletes one of your sent messages
+func (c *APIClient) DeleteMessage(messageID int) error {
+	req, err := c.newRequest("DELETE", "/auth/message/"+strconv.Itoa(messageID), nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.do(req)
+	return err
+}
+
+// EditMessageRequest represents the request payload for editing a message
+type EditMessageRequest struct {
+	Message string `json:"message"`
+}
+
+// EditMessage replaces the text of one of your sent messages
+func (c *APIClient) EditMessage(messageID int, message string) error {
+	req, err := c.newRequest("PUT", "/auth/message/"+strconv.Itoa(messageID), EditMessageRequest{Message: message})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.do(req)
+	return err
+}
+
+// ReactionRequest represents the request payload for reacting to a message
+type ReactionRequest struct {
+	Emoji string `json:"emoji"`
+}
+
+// ReactToMessage adds an emoji reaction to a message
+func (c *APIClient) ReactToMessage(messageID int, emoji string) error {
+	req, err := c.newRequest("POST", "/auth/message/"+strconv.Itoa(messageID)+"/react", ReactionRequest{Emoji: emoji})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.do(req)
+	return err
+}
+
+// ChangePasswordRequest represents the request payload for changing your password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// ChangePassword replaces the logged-in user's password
+func (c *APIClient) ChangePassword(currentPassword, newPassword string) error {
+	req, err := c.newRequest("POST", "/auth/change_password", ChangePasswordRequest{
+		CurrentPassword: currentPassword,
+		NewPassword:     newPassword,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.do(req)
+	return err
+}
+
+// DeleteAccount permanently deletes the logged-in user's account
+func (c *APIClient) DeleteAccount() error {
+	req, err := c.newRequest("DELETE", "/auth/account", nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.do(req)
+	return err
+}
+
+// UploadResponse represents the API response for an uploaded file
+type UploadResponse struct {
+	URL      string `json:"url"`
+	Filename string `json:"filename"`
+}
+
+// UploadFile uploads the file at path as a multipart form and returns where it is stored
+func (c *APIClient) UploadFile(path string) (*UploadResponse, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open attachment: %v", err)
+	}
+	defer file.Close()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filepath.Base(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build upload: %v", err)
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return nil, fmt.Errorf("failed to read attachment: %v", err)
+	}
+	if err := form.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build upload: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(c.Context, "POST", c.BaseURL+"/auth/upload", &body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	respBody, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var upload UploadResponse
+	if err := json.Unmarshal(respBody, &upload); err != nil {
+		return nil, fmt.Errorf("failed to parse upload response: %v", err)
+	}
+	if upload.URL == "" {
+		return nil, fmt.Errorf("upload response did not include a URL")
+	}
+	return &upload, nil
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestSendMessage(t *testing.T) {
+	var got MessageRequest
+	newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/auth/send_message" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer tok" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		writeJSON(t, w, http.StatusCreated, MessageResponse{MessageID: 7, Sender: "1", Recipient: "2"})
+	})
+
+	if err := sendMessage("tok", MessageRequest{Message: "hi", RecipientUserID: "2"}); err != nil {
+		t.Fatalf("sendMessage: %v", err)
+	}
+	if got.Message != "hi" || got.RecipientUserID != "2" {
+		t.Errorf("server received %+v", got)
+	}
+}
+
+func TestSendMessageAPIError(t *testing.T) {
+	newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "recipient is not a friend", http.StatusForbidden)
+	})
+
+	err := sendMessage("tok", MessageRequest{Message: "hi", RecipientUserID: "9"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("sendMessage error = %v, want an API error with status 403", err)
+	}
+}
+
+func TestSearchUser(t *testing.T) {
+	newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/auth/search_user" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if username := r.Header.Get("username"); username != "alice" {
+			t.Errorf("username header = %q", username)
+		}
+		writeJSON(t, w, http.StatusOK, map[string]any{
+			"message":   "User found",
+			"user_data": map[string]string{"user_id": "2", "username": "alice"},
+		})
+	})
+
+	resp, err := searchUser("alice", "tok")
+	if err != nil {
+		t.Fatalf("searchUser: %v", err)
+	}
+	if resp.UserData.UserID != "2" || resp.UserData.Username != "alice" {
+		t.Errorf("user data = %+v", resp.UserData)
+	}
+}
+
+func TestSearchUserNotFound(t *testing.T) {
+	newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"User not found"}`, http.StatusNotFound)
+	})
+
+	_, err := searchUser("nobody", "tok")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("searchUser error = %v, want an API error with status 404", err)
+	}
+}
+
+func TestFetchConversation(t *testing.T) {
+	var markedIDs []int
+	newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/conversation/2":
+			writeJSON(t, w, http.StatusOK, ConversationResponse{
+				Conversation: []Message{
+					{MessageID: 1, Sender: "1", Recipient: "2", Message: "hi", Timestamp: "2026-01-01T10:00:00"},
+					{MessageID: 2, Sender: "2", Recipient: "1", Message: "hello", Timestamp: "2026-01-01T10:01:00"},
+				},
+				Participants:  []string{"1", "2"},
+				TotalMessages: 2,
+			})
+		case "/auth/mark_read":
+			var request MarkReadRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				t.Errorf("decoding mark_read request: %v", err)
+			}
+			markedIDs = request.MessageIDs
+			writeJSON(t, w, http.StatusOK, map[string]string{"message": "ok"})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	})
+
+	token := &TokenData{Token: "tok", UserID: "1", Username: "me"}
+	friend := &Friend{FriendID: "2", FriendUsername: "alice"}
+	if err := fetchConversation(token, friend); err != nil {
+		t.Fatalf("fetchConversation: %v", err)
+	}
+	if len(markedIDs) != 1 || markedIDs[0] != 2 {
+		t.Errorf("marked read %v, want [2]", markedIDs)
+	}
+}
+
+func TestFetchConversationMalformed(t *testing.T) {
+	newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html>gateway</html>"))
+	})
+
+	token := &TokenData{Token: "tok", UserID: "1"}
+	friend := &Friend{FriendID: "2", FriendUsername: "alice"}
+	if err := fetchConversation(token, friend); err == nil {
+		t.Fatal("fetchConversation succeeded on a non-JSON body")
+	}
+}
+
+func TestFetchFriendsFromAPI(t *testing.T) {
+	newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/auth/get_friends" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		writeJSON(t, w, http.StatusOK, map[string]any{
+			"friends": []map[string]any{
+				{"friend_id": "2", "friend_username": "alice", "friendship_id": 1},
+				{"user_id": "3", "username": "bob"},
+			},
+			"total_friends": 2,
+		})
+	})
+
+	friends, err := fetchFriendsFromAPI("tok")
+	if err != nil {
+		t.Fatalf("fetchFriendsFromAPI: %v", err)
+	}
+	if len(friends.Friends) != 2 {
+		t.Fatalf("got %d friends, want 2", len(friends.Friends))
+	}
+	if friends.Friends[1].GetUserID() != "3" || friends.Friends[1].GetUsername() != "bob" {
+		t.Errorf("second friend = %+v", friends.Friends[1])
+	}
+
+	// The list is cached for --offline
+	cached, err := loadFriends("tok", true)
+	if err != nil {
+		t.Fatalf("loading cached friends: %v", err)
+	}
+	if len(cached.Friends) != 2 {
+		t.Errorf("cached %d friends, want 2", len(cached.Friends))
+	}
+}
+
+func TestRespondToFriendRequest(t *testing.T) {
+	var got map[string]string
+	newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/auth/respond_friend_request" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		writeJSON(t, w, http.StatusOK, map[string]string{"message": "Friend request accepted"})
+	})
+
+	if err := respondToFriendRequest(&TokenData{Token: "tok"}, "alice", "accept"); err != nil {
+		t.Fatalf("respondToFriendRequest: %v", err)
+	}
+	if got["username"] != "alice" || got["action"] != "accept" {
+		t.Errorf("server received %v", got)
+	}
+}
+
+func TestRespondToFriendRequestAPIError(t *testing.T) {
+	newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"No pending request"}`, http.StatusBadRequest)
+	})
+
+	err := respondToFriendRequest(&TokenData{Token: "tok"}, "alice", "reject")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("respondToFriendRequest error = %v, want an API error with status 400", err)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+)
+
+// maxAttachmentSize is the largest file that can be attached to a message
+const maxAttachmentSize = 10 << 20
+
+// checkAttachment verifies that the file at filePath can be attached before it is uploaded
+func checkAttachment(filePath string) error {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return fmt.Errorf("cannot attach %s: %v", filePath, err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("cannot attach %s: it is a directory", filePath)
+	}
+	if info.Size() > maxAttachmentSize {
+		return fmt.Errorf("cannot attach %s: %d MB is over the %d MB limit", filePath, info.Size()>>20, maxAttachmentSize>>20)
+	}
+	return nil
+}
+
+// attachmentName returns the file name shown for an attachment URL
+func attachmentName(attachment string) string {
+	if parsed, err := url.Parse(attachment); err == nil && parsed.Path != "" {
+		return path.Base(parsed.Path)
+	}
+	return path.Base(attachment)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// maxPollInterval caps how far polling backs off while no new messages arrive
+const maxPollInterval = 30 * time.Second
+
+// pollJitter is the fraction by which each poll wait is randomly varied, so
+// clients started together do not keep polling the server in step
+const pollJitter = 0.2
+
+// pollBackoff works out the wait between conversation polls. It starts at the
+// configured interval, doubles after each poll that found nothing new up to
+// maxPollInterval, and drops back to the configured interval once messages arrive.
+type pollBackoff struct {
+	base    time.Duration
+	current time.Duration
+}
+
+func newPollBackoff(base time.Duration) *pollBackoff {
+	return &pollBackoff{base: base, current: base}
+}
+
+// next returns the wait before the next poll, varied by up to ±pollJitter
+func (b *pollBackoff) next() time.Duration {
+	jitter := 1 + pollJitter*(2*rand.Float64()-1)
+	return time.Duration(float64(b.current) * jitter)
+}
+
+// update records whether the last poll found new messages
+func (b *pollBackoff) update(foundNew bool) {
+	if foundNew {
+		b.current = b.base
+		return
+	}
+	b.current = min(b.current*2, max(maxPollInterval, b.base))
+}
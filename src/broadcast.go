@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// maxConcurrentSends bounds how many broadcast messages are sent at once
+const maxConcurrentSends = 5
+
+// BroadcastResult is the outcome of sending a broadcast message to one friend
+type BroadcastResult struct {
+	FriendUsername string `json:"friend_username"`
+	FriendID       string `json:"friend_id"`
+	Sent           bool   `json:"sent"`
+	Error          string `json:"error,omitempty"`
+}
+
+// broadcast implements the broadcast command: send the same message to several friends
+func broadcast() error {
+	args := os.Args[2:]
+	var message string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--") {
+			message = arg
+			break
+		}
+	}
+
+	token, err := requireToken()
+	if err != nil {
+		return err
+	}
+
+	friends, err := loadFriends(token.Token, hasFlag(args, "--offline"))
+	if err != nil {
+		return fmt.Errorf("error fetching friends: %v", err)
+	}
+	if len(friends.Friends) == 0 {
+		return fmt.Errorf("no friends found in your friends list")
+	}
+
+	recipients, err := selectFriends(friends)
+	if err != nil {
+		return err
+	}
+
+	if message == "" {
+		promptf("Composing message to %d friends\n", len(recipients))
+		message, err = composeMessage(stdinReader)
+		if err != nil {
+			return fmt.Errorf("error reading message: %v", err)
+		}
+		if message == "" {
+			return fmt.Errorf("message cannot be empty")
+		}
+	}
+
+	if hasFlag(args, "--confirm") || loadConfig().ConfirmSend {
+		promptf("Send to %d friends? [y/N]: ", len(recipients))
+		answer, _ := readLine()
+		if answer = strings.ToLower(answer); answer != "y" && answer != "yes" {
+			infoln("Message not sent.")
+			return nil
+		}
+	}
+
+	results := sendBroadcast(token, recipients, message)
+	failed := 0
+	for _, result := range results {
+		if !result.Sent {
+			failed++
+		}
+	}
+
+	if jsonOutput {
+		if err := printJSON(results); err != nil {
+			return err
+		}
+	} else {
+		displayBroadcastResults(results)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d messages could not be sent", failed, len(results))
+	}
+	return nil
+}
+
+// selectFriends lists the friends and asks for several of them, as comma-separated
+// list numbers or "all"
+func selectFriends(friends *FriendsData) ([]*Friend, error) {
+	promptf("\n--- Your Friends ---\n")
+	for i, friend := range friends.Friends {
+		promptf("%d. %s (ID: %s)%s\n", i+1, friend.GetUsername(), friend.GetUserID(), unsyncedNote(&friend))
+	}
+	promptf("\nEnter the numbers of the friends to message, separated by commas, or \"all\": ")
+	input, _ := readLine()
+
+	if strings.EqualFold(input, "all") {
+		selected := make([]*Friend, len(friends.Friends))
+		for i := range friends.Friends {
+			selected[i] = &friends.Friends[i]
+		}
+		return selected, nil
+	}
+
+	var selected []*Friend
+	chosen := make(map[int]bool)
+	for _, field := range strings.Split(input, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		choice, err := strconv.Atoi(field)
+		if err != nil || choice < 1 || choice > len(friends.Friends) {
+			return nil, fmt.Errorf("invalid choice %q: please select numbers between 1 and %d", field, len(friends.Friends))
+		}
+		if !chosen[choice] {
+			chosen[choice] = true
+			selected = append(selected, &friends.Friends[choice-1])
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no friends selected")
+	}
+	return selected, nil
+}
+
+// sendBroadcast sends message to every recipient using up to maxConcurrentSends
+// concurrent requests. A failed send does not stop the others; the results are
+// returned in the order of recipients.
+func sendBroadcast(token *TokenData, recipients []*Friend, message string) []BroadcastResult {
+	client := NewAPIClient(token.Token)
+	results := make([]BroadcastResult, len(recipients))
+	slots := make(chan struct{}, maxConcurrentSends)
+
+	var wg sync.WaitGroup
+	for i, friend := range recipients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			result := BroadcastResult{FriendUsername: friend.GetUsername(), FriendID: friend.GetUserID()}
+			if _, err := client.SendMessage(message, friend.GetUserID()); err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Sent = true
+			}
+			results[i] = result
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// displayBroadcastResults prints whether the message reached each friend
+func displayBroadcastResults(results []BroadcastResult) {
+	sent := 0
+	fmt.Println("\n=== Broadcast Results ===")
+	for _, result := range results {
+		if result.Sent {
+			sent++
+			fmt.Printf("✅ %s\n", result.FriendUsername)
+		} else {
+			fmt.Printf("❌ %s: %s\n", result.FriendUsername, result.Error)
+		}
+	}
+	fmt.Printf("\nSent to %d of %d friends.\n", sent, len(results))
+}
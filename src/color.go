@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"regexp"
+	"unicode"
+
+	"golang.org/x/term"
+)
+
+// ANSI escape sequences used for colored output
+const (
+	ansiReset      = "\033[0m"
+	ansiDim        = "\033[2m"
+	ansiCyan       = "\033[36m"
+	ansiGreen      = "\033[32m"
+	ansiReverse    = "\033[7m"
+	ansiReverseOff = "\033[27m"
+)
+
+// colorEnabled controls ANSI coloring. It is off when NO_COLOR is set, when stdout
+// is not a terminal, or with --no-color.
+var colorEnabled = os.Getenv("NO_COLOR") == "" && term.IsTerminal(int(os.Stdout.Fd()))
+
+// ansiPattern matches the escape sequences written by colorize
+var ansiPattern = regexp.MustCompile("\033\\[[0-9;]*m")
+
+// colorize wraps text in the given ANSI code when color is enabled
+func colorize(code, text string) string {
+	if !colorEnabled || text == "" {
+		return text
+	}
+	return code + text + ansiReset
+}
+
+// visibleLen returns the number of terminal cells s takes up on screen, ignoring
+// ANSI escape sequences. Emoji and CJK characters are two cells wide.
+func visibleLen(s string) int {
+	width := 0
+	for _, r := range ansiPattern.ReplaceAllString(s, "") {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// runeWidth returns the number of terminal cells r takes up: 0 for combining
+// marks, variation selectors and joiners, 2 for wide characters, 1 otherwise
+func runeWidth(r rune) int {
+	switch {
+	case r == 0x200D, r >= 0xFE00 && r <= 0xFE0F, unicode.In(r, unicode.Mn, unicode.Me):
+		return 0
+	case r >= 0x1100 && r <= 0x115F, // Hangul Jamo
+		r >= 0x2E80 && r <= 0xA4CF, // CJK
+		r >= 0xAC00 && r <= 0xD7A3, // Hangul syllables
+		r >= 0xF900 && r <= 0xFAFF, // CJK compatibility ideographs
+		r >= 0xFF00 && r <= 0xFF60, // fullwidth forms
+		r >= 0xFFE0 && r <= 0xFFE6,
+		r >= 0x1F300 && r <= 0x1F64F, // pictographs and emoticons
+		r >= 0x1F680 && r <= 0x1F6FF, // transport and map symbols
+		r >= 0x1F900 && r <= 0x1F9FF, // supplemental symbols and pictographs
+		r >= 0x20000 && r <= 0x3FFFD: // CJK extensions
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// defaultBaseURL is the production backend
+const defaultBaseURL = "https://wasalbackend-production.up.railway.app"
+
+// defaultProfile is the profile used without --profile or CHAT_APP_PROFILE
+const defaultProfile = "production"
+
+// Config represents the optional settings in ~/.config/chat_app/config.json
+type Config struct {
+	BaseURL      string            `json:"base_url,omitempty"`
+	ExtraHeaders map[string]string `json:"extra_headers,omitempty"`
+	// HTTPTimeout is a duration such as "30s" or a number of seconds
+	HTTPTimeout string `json:"http_timeout,omitempty"`
+	// ConfirmSend asks for confirmation of the recipient before every message
+	ConfirmSend bool `json:"confirm_send,omitempty"`
+	// HideRequestBadge stops login from showing the number of pending friend requests
+	HideRequestBadge bool `json:"hide_request_badge,omitempty"`
+	// Theme picks the message indicators: "emoji", "ascii" or "plain"; by default
+	// emoji on UTF-8 locales and ascii elsewhere
+	Theme string `json:"theme,omitempty"`
+	// TokenStorage is "keyring" to keep tokens in the system keyring, or "file" (the default)
+	TokenStorage string `json:"token_storage,omitempty"`
+	// Profiles are named backends selected with --profile, such as "staging"
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+}
+
+// Profile is a named backend environment with its own saved session
+type Profile struct {
+	BaseURL string `json:"base_url,omitempty"`
+	// TokenFile defaults to token.<profile>.json; relative paths are inside the config directory
+	TokenFile string `json:"token_file,omitempty"`
+}
+
+var (
+	configOnce sync.Once
+	appConfig  Config
+)
+
+// loadConfig returns the user's config, reading it on first use.
+// A missing config file yields the defaults.
+func loadConfig() *Config {
+	configOnce.Do(func() {
+		dir, err := configDir()
+		if err != nil {
+			return
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+		if err != nil {
+			if !os.IsNotExist(err) {
+				errorf("Warning: failed to read config file: %v\n", err)
+			}
+			return
+		}
+
+		if err := json.Unmarshal(data, &appConfig); err != nil {
+			errorf("Warning: failed to parse config file: %v\n", err)
+		}
+	})
+	return &appConfig
+}
+
+// activeProfile returns the profile selected with --profile or CHAT_APP_PROFILE
+func activeProfile() string {
+	if profile != "" {
+		return profile
+	}
+	if name := os.Getenv("CHAT_APP_PROFILE"); name != "" {
+		return name
+	}
+	return defaultProfile
+}
+
+// profileConfig returns the settings of the active profile. The default profile
+// needs no entry in the config file; any other profile must be defined there.
+func profileConfig() (Profile, error) {
+	name := activeProfile()
+	settings, ok := loadConfig().Profiles[name]
+	if !ok && name != defaultProfile {
+		return Profile{}, fmt.Errorf("unknown profile %q: add it under \"profiles\" in config.json", name)
+	}
+	return settings, nil
+}
+
+// apiBaseURL returns the backend URL from CHAT_APP_BASE_URL, the active profile,
+// the config file, or the default
+func apiBaseURL() string {
+	baseURL := os.Getenv("CHAT_APP_BASE_URL")
+	if baseURL == "" {
+		settings, _ := profileConfig()
+		baseURL = settings.BaseURL
+	}
+	if baseURL == "" {
+		baseURL = loadConfig().BaseURL
+	}
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return strings.TrimRight(baseURL, "/")
+}
+
+// apiURL returns the full URL for an API path such as "/auth/get_friends"
+func apiURL(path string) string {
+	return apiBaseURL() + path
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// importDelay spaces out requests during an import to stay clear of rate limits
+const importDelay = 500 * time.Millisecond
+
+// Import outcomes reported per username
+const (
+	importSent           = "sent"
+	importAlreadyFriends = "already friends"
+	importPending        = "request pending"
+	importNotFound       = "not found"
+	importError          = "error"
+)
+
+// ImportResult is the outcome of importing a single username
+type ImportResult struct {
+	Username string
+	Status   string
+	Detail   string
+}
+
+// manageContacts implements the contacts command
+func manageContacts() error {
+	if len(os.Args) < 4 || os.Args[2] != "import" {
+		return fmt.Errorf("usage: go run main.go contacts import <file.csv|file.json>")
+	}
+
+	return importContactsFile(os.Args[3])
+}
+
+// invite implements the invite command, which sends friend requests to the
+// usernames listed one per line in the --file given
+func invite() error {
+	path, ok := flagValue(os.Args[2:], "--file")
+	if !ok || path == "" {
+		return fmt.Errorf("usage: go run main.go invite --file <usernames.txt>")
+	}
+
+	return importContactsFile(path)
+}
+
+// importContactsFile sends friend requests to the usernames in the file at path
+// and prints the results. It fails only when no username could be imported.
+func importContactsFile(path string) error {
+	usernames, err := readContactsFile(path)
+	if err != nil {
+		return err
+	}
+	if len(usernames) == 0 {
+		return fmt.Errorf("no usernames found in %s", path)
+	}
+
+	token, err := requireToken()
+	if err != nil {
+		return err
+	}
+
+	results, err := importContacts(token, usernames)
+	if err != nil {
+		return err
+	}
+
+	displayImportResults(results)
+
+	for _, result := range results {
+		if result.Status != importError && result.Status != importNotFound {
+			return nil
+		}
+	}
+	return fmt.Errorf("no usernames could be imported")
+}
+
+// importContacts sends a friend request to each username, skipping existing friends
+// and pending requests, and returns one result per username
+func importContacts(token *TokenData, usernames []string) ([]ImportResult, error) {
+	// Gather existing friends and pending requests so duplicates are not re-sent
+	friends, err := fetchFriendsFromAPI(token.Token)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching friends: %v", err)
+	}
+	friendNames := make(map[string]bool)
+	for _, friend := range friends.Friends {
+		friendNames[strings.ToLower(friend.GetUsername())] = true
+	}
+
+	outgoing, err := fetchOutgoingFriendRequests(token, apiURL("/auth/get_outgoing_friend_requests"))
+	if err != nil {
+		return nil, fmt.Errorf("error fetching outgoing requests: %v", err)
+	}
+	pendingNames := make(map[string]bool)
+	for _, request := range outgoing.OutgoingRequests {
+		if strings.EqualFold(request.Status, "pending") {
+			pendingNames[strings.ToLower(request.RecipientUsername)] = true
+		}
+	}
+
+	var results []ImportResult
+	for i, username := range usernames {
+		fmt.Printf("\n[%d/%d] %s\n", i+1, len(usernames), username)
+
+		key := strings.ToLower(username)
+		switch {
+		case strings.EqualFold(username, token.Username):
+			results = append(results, ImportResult{Username: username, Status: importError, Detail: "cannot add yourself"})
+			continue
+		case friendNames[key]:
+			results = append(results, ImportResult{Username: username, Status: importAlreadyFriends})
+			continue
+		case pendingNames[key]:
+			results = append(results, ImportResult{Username: username, Status: importPending})
+			continue
+		}
+
+		if i > 0 {
+			time.Sleep(importDelay)
+		}
+		results = append(results, importContact(token, username))
+		pendingNames[key] = true
+	}
+
+	return results, nil
+}
+
+// importContact looks up a single username and sends it a friend request
+func importContact(token *TokenData, username string) ImportResult {
+	userInfo, err := searchUser(username, token.Token)
+	if err != nil {
+		if isAPIStatus(err, http.StatusNotFound) {
+			return ImportResult{Username: username, Status: importNotFound}
+		}
+		return ImportResult{Username: username, Status: importError, Detail: err.Error()}
+	}
+
+	err = sendFriendRequest(userInfo.UserData.Username, token.Token)
+	if err != nil {
+		if isAPIStatus(err, http.StatusConflict) {
+			return ImportResult{Username: username, Status: importPending}
+		}
+		return ImportResult{Username: username, Status: importError, Detail: err.Error()}
+	}
+
+	return ImportResult{Username: username, Status: importSent}
+}
+
+// displayImportResults prints a per-username result table and a summary
+func displayImportResults(results []ImportResult) {
+	fmt.Println("\n=== Import Results ===")
+	counts := make(map[string]int)
+	for _, result := range results {
+		counts[result.Status]++
+		line := fmt.Sprintf("%-24s %s", result.Username, result.Status)
+		if result.Detail != "" {
+			line += ": " + result.Detail
+		}
+		fmt.Println(line)
+	}
+
+	fmt.Println(strings.Repeat("-", 40))
+	fmt.Printf("Sent: %d, Already friends: %d, Pending: %d, Not found: %d, Errors: %d\n",
+		counts[importSent], counts[importAlreadyFriends], counts[importPending], counts[importNotFound], counts[importError])
+}
+
+// readContactsFile reads usernames from a JSON or CSV file.
+// JSON may be a list of strings or of objects with a "username" field;
+// CSV uses the first column, ignoring a "username" header, comments and blank lines.
+func readContactsFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read contacts file: %v", err)
+	}
+
+	var usernames []string
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		usernames, err = parseContactsJSON(data)
+	} else {
+		usernames, err = parseContactsCSV(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop duplicates while keeping the file order
+	seen := make(map[string]bool)
+	var unique []string
+	for _, username := range usernames {
+		key := strings.ToLower(username)
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, username)
+		}
+	}
+	return unique, nil
+}
+
+// parseContactsJSON parses a JSON list of usernames or of {"username": ...} objects
+func parseContactsJSON(data []byte) ([]string, error) {
+	var names []string
+	if err := json.Unmarshal(data, &names); err == nil {
+		return cleanUsernames(names), nil
+	}
+
+	var entries []struct {
+		Username string `json:"username"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse contacts JSON: expected a list of usernames or objects with a \"username\" field")
+	}
+	for _, entry := range entries {
+		names = append(names, entry.Username)
+	}
+	return cleanUsernames(names), nil
+}
+
+// parseContactsCSV returns the first column of each CSV record, skipping malformed lines
+func parseContactsCSV(data []byte) ([]string, error) {
+	reader := csv.NewReader(strings.NewReader(string(data)))
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	var names []string
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				fmt.Printf("Skipping malformed line %d: %v\n", parseErr.Line, parseErr.Err)
+				continue
+			}
+			return nil, fmt.Errorf("failed to read contacts CSV: %v", err)
+		}
+		if len(record) == 0 {
+			continue
+		}
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "username") {
+			continue
+		}
+		names = append(names, record[0])
+	}
+
+	return cleanUsernames(names), nil
+}
+
+// cleanUsernames trims usernames and drops empty or space-containing entries
+func cleanUsernames(names []string) []string {
+	var cleaned []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if strings.ContainsAny(name, " \t") {
+			errorf("Skipping invalid username %q\n", name)
+			continue
+		}
+		cleaned = append(cleaned, name)
+	}
+	return cleaned
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// maxConcurrentFetches bounds how many conversations are fetched at once
+const maxConcurrentFetches = 5
+
+// fetchAllTimeout bounds how long fetching every conversation may take in total
+const fetchAllTimeout = 60 * time.Second
+
+// fetchAllConversations fetches the conversation with every friend concurrently
+// within fetchAllTimeout. Failed fetches are reported and skipped, and when the
+// timeout expires the conversations fetched so far are returned.
+func fetchAllConversations(token *TokenData, friends *FriendsData) map[string]*ConversationResponse {
+	ctx, cancel := context.WithTimeout(appContext(), fetchAllTimeout)
+	defer cancel()
+
+	conversations, err := fetchConversations(ctx, token, friends)
+	if err != nil {
+		errorf("Warning: %v, showing %d of %d conversations\n", err, len(conversations), len(friends.Friends))
+	}
+	return conversations
+}
+
+// conversationResult is the outcome of fetching the conversation with one friend
+type conversationResult struct {
+	friend       *Friend
+	conversation *ConversationResponse
+	err          error
+}
+
+// fetchConversations fetches the conversation with every friend using a pool of
+// maxConcurrentFetches workers and returns them keyed by friend ID. If ctx is
+// done before every fetch finishes, the fetches in flight are aborted and the
+// conversations fetched so far are returned together with the context's error.
+func fetchConversations(ctx context.Context, token *TokenData, friends *FriendsData) (map[string]*ConversationResponse, error) {
+	jobs := make(chan *Friend)
+	// Buffered so workers never block once the results are no longer collected
+	results := make(chan conversationResult, len(friends.Friends))
+
+	client := NewAPIClient(token.Token)
+	client.Context = ctx
+
+	for range min(maxConcurrentFetches, len(friends.Friends)) {
+		go func() {
+			for friend := range jobs {
+				conversation, err := client.GetConversation(friend.GetUserID())
+				results <- conversationResult{friend, conversation, err}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for i := range friends.Friends {
+			select {
+			case jobs <- &friends.Friends[i]:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	conversations := make(map[string]*ConversationResponse)
+	for range friends.Friends {
+		select {
+		case result := <-results:
+			if result.err != nil {
+				errorf("Warning: skipping conversation with %s: %v\n", result.friend.GetUsername(), result.err)
+				continue
+			}
+			conversations[result.friend.GetUserID()] = result.conversation
+		case <-ctx.Done():
+			return conversations, fmt.Errorf("fetching conversations stopped: %v", ctx.Err())
+		}
+	}
+
+	return conversations, nil
+}
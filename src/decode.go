@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// maxBodySnippet is how much of a response body is quoted in a parse error
+const maxBodySnippet = 200
+
+// The backend has not always been consistent about the JSON types of some
+// fields, such as IDs sent as either numbers or strings. The flex types below
+// accept either form so a change on the server does not break every command.
+
+// flexInt decodes a JSON number or a string holding a number
+type flexInt int
+
+func (n *flexInt) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		value = string(data)
+	}
+	if value == "" || value == "null" {
+		*n = 0
+		return nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("expected a number, got %s", data)
+	}
+	*n = flexInt(parsed)
+	return nil
+}
+
+// flexString decodes a JSON string, or any other scalar as its literal text
+type flexString string
+
+func (s *flexString) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		*s = flexString(value)
+		return nil
+	}
+	if bytes.Equal(data, []byte("null")) {
+		*s = ""
+		return nil
+	}
+	if len(data) > 0 && (data[0] == '{' || data[0] == '[') {
+		return fmt.Errorf("expected a string, got %s", data)
+	}
+	*s = flexString(data)
+	return nil
+}
+
+// flexBool decodes a JSON boolean, a number (non-zero is true) or a string such as "true" or "1"
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		value = string(data)
+	}
+	switch value {
+	case "", "null":
+		*b = false
+		return nil
+	}
+	if parsed, err := strconv.ParseBool(value); err == nil {
+		*b = flexBool(parsed)
+		return nil
+	}
+	if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+		*b = parsed != 0
+		return nil
+	}
+	return fmt.Errorf("expected a boolean, got %s", data)
+}
+
+// UnmarshalJSON decodes a message, accepting IDs and flags in either JSON type
+func (m *Message) UnmarshalJSON(data []byte) error {
+	type plainMessage Message
+	aux := struct {
+		*plainMessage
+		MessageID flexInt    `json:"message_id"`
+		IsRead    flexBool   `json:"is_read"`
+		Sender    flexString `json:"sender"`
+		Recipient flexString `json:"recipient"`
+		Edited    flexBool   `json:"edited"`
+		Reactions reactions  `json:"reactions"`
+	}{plainMessage: (*plainMessage)(m)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	m.MessageID = int(aux.MessageID)
+	m.IsRead = bool(aux.IsRead)
+	m.Sender = string(aux.Sender)
+	m.Recipient = string(aux.Recipient)
+	m.Edited = bool(aux.Edited)
+	m.Reactions = aux.Reactions
+	return nil
+}
+
+// reactions decodes message reactions given either as counts keyed by emoji,
+// {"👍": 2}, or as a list of {"emoji": "👍", "count": 2} entries, where a missing
+// count means a single reaction, such as one entry per user who reacted
+type reactions map[string]int
+
+func (r *reactions) UnmarshalJSON(data []byte) error {
+	var counts map[string]flexInt
+	if err := json.Unmarshal(data, &counts); err == nil {
+		*r = make(reactions, len(counts))
+		for emoji, count := range counts {
+			(*r)[emoji] = int(count)
+		}
+		return nil
+	}
+
+	var entries []struct {
+		Emoji string   `json:"emoji"`
+		Count *flexInt `json:"count"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("expected reactions as an object or a list, got %s", data)
+	}
+	*r = make(reactions)
+	for _, entry := range entries {
+		count := 1
+		if entry.Count != nil {
+			count = int(*entry.Count)
+		}
+		(*r)[entry.Emoji] += count
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes a conversation, accepting the total and participant IDs in either JSON type
+func (c *ConversationResponse) UnmarshalJSON(data []byte) error {
+	type plainConversation ConversationResponse
+	aux := struct {
+		*plainConversation
+		Participants  []flexString `json:"participants"`
+		TotalMessages flexInt      `json:"total_messages"`
+	}{plainConversation: (*plainConversation)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	c.Participants = nil
+	for _, participant := range aux.Participants {
+		c.Participants = append(c.Participants, string(participant))
+	}
+	c.TotalMessages = int(aux.TotalMessages)
+	return nil
+}
+
+// UnmarshalJSON decodes a login response, accepting the user ID and expiry as strings or numbers
+func (r *LoginResponse) UnmarshalJSON(data []byte) error {
+	type plainLoginResponse LoginResponse
+	aux := struct {
+		*plainLoginResponse
+		ExpiresIn flexString `json:"expires_in"`
+		UserID    flexString `json:"user_id"`
+	}{plainLoginResponse: (*plainLoginResponse)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.ExpiresIn = string(aux.ExpiresIn)
+	r.UserID = string(aux.UserID)
+	return nil
+}
+
+// parseError describes a response body that could not be decoded, quoting the
+// start of the body (with secrets redacted) so the unexpected shape is visible
+func parseError(err error, body []byte) error {
+	text := []rune(redactBody(body))
+	if len(text) > maxBodySnippet {
+		text = append(text[:maxBodySnippet], '…')
+	}
+	return fmt.Errorf("failed to parse response: %v (response body: %s)", err, string(text))
+}
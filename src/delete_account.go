@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// accountStateFiles are the local files that belong to the logged-in account and
+// are removed with it. config.json is kept since it holds settings and profiles.
+var accountStateFiles = []string{"friends.json", "seen.json", outboxFile, lastMessageFile, aliasesFile}
+
+// deleteAccount permanently deletes the logged-in account. It needs both the
+// --yes-really flag and the username typed back, then removes the local token
+// and cached account data.
+func deleteAccount() error {
+	token, err := requireToken()
+	if err != nil {
+		return err
+	}
+
+	if !hasFlag(os.Args[2:], "--yes-really") {
+		return fmt.Errorf("this permanently deletes your account; run `delete-account --yes-really` to continue")
+	}
+
+	fmt.Printf("⚠️  This will permanently delete the account %s and all of its messages.\n", token.Username)
+	fmt.Print("Type your username to confirm: ")
+	answer, _ := readLine()
+	if answer != token.Username {
+		fmt.Println("Username did not match. Your account was not deleted.")
+		return nil
+	}
+
+	if err := NewAPIClient(token.Token).DeleteAccount(); err != nil {
+		return err
+	}
+	if dryRun {
+		fmt.Println("[dry-run] local token and account data kept")
+		return nil
+	}
+
+	// The account is gone; clean up what is stored locally for it
+	tokenFile, err := tokenFilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(tokenFile); err != nil && !os.IsNotExist(err) {
+		errorf("Warning: could not remove %s: %v\n", tokenFile, err)
+	}
+	if useKeyring() {
+		deleteKeyringToken()
+	}
+	for _, name := range accountStateFiles {
+		if err := removeStateFile(name); err != nil {
+			errorf("Warning: %v\n", err)
+		}
+	}
+
+	fmt.Printf("Account %s has been deleted and its local data removed. Goodbye!\n", token.Username)
+	return nil
+}
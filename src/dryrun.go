@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// dryRunResponseBody is the body of the response faked for a request skipped by
+// --dry-run. The placeholder URL lets a dry-run upload carry on to the send.
+const dryRunResponseBody = `{"message": "dry run", "url": "dry-run://upload"}`
+
+// isMutating reports whether req changes state on the server. Only GET and HEAD
+// requests are reads; they still run with --dry-run.
+func isMutating(req *http.Request) bool {
+	return req.Method != http.MethodGet && req.Method != http.MethodHead
+}
+
+// dryRunResponse prints what req would have sent and returns a successful response
+// in its place, without contacting the server. Sensitive body fields are redacted.
+func dryRunResponse(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %v", err)
+		}
+	}
+
+	if strings.HasPrefix(req.Header.Get("Content-Type"), "multipart/") {
+		fmt.Fprintf(os.Stderr, "[dry-run] would %s %s with a %d byte upload\n", req.Method, req.URL, len(body))
+	} else if len(body) > 0 {
+		fmt.Fprintf(os.Stderr, "[dry-run] would %s %s with body %s\n", req.Method, req.URL, redactBody(body))
+	} else {
+		fmt.Fprintf(os.Stderr, "[dry-run] would %s %s\n", req.Method, req.URL)
+	}
+
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(bytes.NewBufferString(dryRunResponseBody)),
+		Request:    req,
+	}, nil
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ExportManifest records which conversations have been exported, keyed by friend ID
+type ExportManifest struct {
+	Exports map[string]ExportRecord `json:"exports"`
+}
+
+// ExportRecord describes one exported conversation
+type ExportRecord struct {
+	FriendUsername string `json:"friend_username"`
+	File           string `json:"file"`
+	MessageCount   int    `json:"message_count"`
+	ExportedAt     string `json:"exported_at"`
+}
+
+// exportConversations implements the export command
+func exportConversations() error {
+	args := os.Args[2:]
+	if !hasFlag(args, "--all") {
+		return fmt.Errorf("usage: go run main.go export --all [--force]")
+	}
+	force := hasFlag(args, "--force")
+
+	token, err := requireToken()
+	if err != nil {
+		return err
+	}
+
+	friends, err := fetchFriendsFromAPI(token.Token)
+	if err != nil {
+		return fmt.Errorf("error fetching friends: %v", err)
+	}
+
+	exportDir, err := exportsDir()
+	if err != nil {
+		return err
+	}
+
+	manifest, err := loadExportManifest(exportDir)
+	if err != nil {
+		return err
+	}
+
+	fileNames := exportFileNames(friends)
+	exported, skipped, failed := 0, 0, 0
+	for i := range friends.Friends {
+		friend := &friends.Friends[i]
+		friendID := friend.GetUserID()
+		fileName := fmt.Sprintf("conversation_%s.txt", fileNames[friendID])
+
+		// Skip conversations whose export is already complete
+		if record, ok := manifest.Exports[friendID]; ok && !force && record.File == fileName && isExportComplete(exportDir, record) {
+			fmt.Printf("⏭  %s: already exported (%d messages)\n", friend.GetUsername(), record.MessageCount)
+			skipped++
+			continue
+		}
+
+		record, err := exportConversation(token, friend, exportDir, fileName)
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", friend.GetUsername(), err)
+			failed++
+			continue
+		}
+
+		// Save the manifest after every export so an interrupted run can resume
+		manifest.Exports[friendID] = *record
+		if err := saveExportManifest(exportDir, manifest); err != nil {
+			return err
+		}
+
+		fmt.Printf("✅ %s: %d messages -> %s\n", friend.GetUsername(), record.MessageCount, record.File)
+		exported++
+	}
+
+	fmt.Printf("\nExported %d, skipped %d, failed %d (of %d conversations)\n", exported, skipped, failed, len(friends.Friends))
+	fmt.Printf("Exports directory: %s\n", exportDir)
+
+	if failed > 0 {
+		return fmt.Errorf("%d conversation(s) could not be exported", failed)
+	}
+	return nil
+}
+
+// exportConversation writes the conversation with friend to fileName in the export
+// directory and verifies that every fetched message was written and can be read
+// back from the file. A file that fails verification is written once more.
+func exportConversation(token *TokenData, friend *Friend, exportDir, fileName string) (*ExportRecord, error) {
+	conversation, err := getConversation(token, friend)
+	if err != nil {
+		return nil, err
+	}
+	messages := filterConversation(token, friend, conversation)
+
+	path := filepath.Join(exportDir, fileName)
+
+	for attempt := 1; ; attempt++ {
+		written, err := writeConversationExport(path, token, friend, messages)
+		if err != nil {
+			return nil, err
+		}
+		if written != len(messages) {
+			return nil, fmt.Errorf("export verification failed: wrote %d of %d messages", written, len(messages))
+		}
+
+		count, err := countExportedMessages(path)
+		if err != nil {
+			return nil, err
+		}
+		if count == written {
+			break
+		}
+		if attempt == 2 {
+			return nil, fmt.Errorf("export verification failed: read back %d of %d messages", count, written)
+		}
+	}
+
+	return &ExportRecord{
+		FriendUsername: friend.GetUsername(),
+		File:           fileName,
+		MessageCount:   len(messages),
+		ExportedAt:     time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// writeConversationExport writes messages to path in the text export format and
+// returns the number of messages written
+func writeConversationExport(path string, token *TokenData, friend *Friend, messages []Message) (int, error) {
+	var sb strings.Builder
+	written := writeConversationText(&sb, token, friend, messages)
+	return written, writeFileAtomic(path, []byte(sb.String()), 0600)
+}
+
+// writeConversationText renders messages as plain text and returns the number of
+// messages written. Each message starts a "[timestamp] sender: text" line and its
+// further lines are indented, so only message lines start with "[" and
+// countExportedMessages can count them.
+func writeConversationText(w io.Writer, token *TokenData, friend *Friend, messages []Message) int {
+	username := singleLine(friend.GetUsername())
+	fmt.Fprintf(w, "Conversation with %s (ID: %s)\n", username, singleLine(friend.GetUserID()))
+	fmt.Fprintf(w, "Exported: %s\n", time.Now().Format("Jan 2, 2006 at 3:04 PM"))
+	fmt.Fprintf(w, "Messages: %d\n", len(messages))
+	fmt.Fprintln(w, strings.Repeat("=", 50))
+
+	written := 0
+	for _, msg := range messages {
+		sender := username
+		if msg.Sender == token.UserID {
+			sender = "You"
+		}
+		text := strings.ReplaceAll(messageText(msg), "\r\n", "\n")
+		text = strings.ReplaceAll(strings.ReplaceAll(text, "\r", "\n"), "\n", "\n    ")
+		fmt.Fprintf(w, "[%s] %s: %s\n", singleLine(msg.Timestamp), sender, text)
+		written++
+	}
+	return written
+}
+
+// singleLine replaces line breaks in s with spaces so it cannot start a new line of an export
+func singleLine(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
+
+// countExportedMessages counts the message lines in a text export
+func countExportedMessages(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open export: %v", err)
+	}
+	defer file.Close()
+
+	count := 0
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "[") {
+			count++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read export: %v", err)
+	}
+
+	return count, nil
+}
+
+// isExportComplete reports whether the export described by record exists with the expected message count
+func isExportComplete(exportDir string, record ExportRecord) bool {
+	count, err := countExportedMessages(filepath.Join(exportDir, record.File))
+	return err == nil && count == record.MessageCount
+}
+
+// sanitizeFilename makes name safe to use as part of a file name by replacing
+// anything other than ASCII letters, digits, '-', '_' and '.' with '_'.
+// It returns "" when nothing usable remains.
+func sanitizeFilename(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('_')
+		}
+	}
+
+	// Avoid hidden files and "." / ".." path components
+	sanitized := strings.TrimLeft(sb.String(), "._")
+	sanitized = strings.TrimRight(sanitized, "._")
+	if len(sanitized) > 64 {
+		sanitized = sanitized[:64]
+	}
+	return sanitized
+}
+
+// friendFileName returns a file-name-safe identifier for friend,
+// falling back to the user ID when the username has no usable characters
+func friendFileName(friend *Friend) string {
+	if name := sanitizeFilename(friend.GetUsername()); name != "" {
+		return name
+	}
+	if id := sanitizeFilename(friend.GetUserID()); id != "" {
+		return id
+	}
+	return "unknown"
+}
+
+// exportFileNames returns the file name identifier of every friend, keyed by user ID.
+// Friends whose usernames sanitize to the same name, ignoring case as some file
+// systems do, get "@" and their user ID appended. sanitizeFilename never produces
+// "@", so the result cannot clash with another friend's name either.
+func exportFileNames(friends *FriendsData) map[string]string {
+	counts := make(map[string]int)
+	for i := range friends.Friends {
+		counts[strings.ToLower(friendFileName(&friends.Friends[i]))]++
+	}
+
+	names := make(map[string]string, len(friends.Friends))
+	for i := range friends.Friends {
+		friend := &friends.Friends[i]
+		name := friendFileName(friend)
+		if counts[strings.ToLower(name)] > 1 {
+			name += "@" + sanitizeFilename(friend.GetUserID())
+		}
+		names[friend.GetUserID()] = name
+	}
+	return names
+}
+
+// exportsDir returns ~/.config/chat_app/exports, creating it if needed
+func exportsDir() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+
+	exportDir := filepath.Join(dir, "exports")
+	if err := os.MkdirAll(exportDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create exports directory: %v", err)
+	}
+	return exportDir, nil
+}
+
+// loadExportManifest reads the export manifest, returning an empty one if none exists
+func loadExportManifest(exportDir string) (*ExportManifest, error) {
+	manifest := &ExportManifest{Exports: make(map[string]ExportRecord)}
+
+	data, err := os.ReadFile(filepath.Join(exportDir, "manifest.json"))
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export manifest: %v", err)
+	}
+
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse export manifest: %v", err)
+	}
+	if manifest.Exports == nil {
+		manifest.Exports = make(map[string]ExportRecord)
+	}
+	return manifest, nil
+}
+
+// saveExportManifest writes the export manifest
+func saveExportManifest(exportDir string, manifest *ExportManifest) error {
+	jsonData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode export manifest: %v", err)
+	}
+	return writeFileAtomic(filepath.Join(exportDir, "manifest.json"), jsonData, 0600)
+}
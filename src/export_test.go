@@ -0,0 +1,130 @@
+package main
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"alice", "alice"},
+		{"../../etc/passwd", "etc_passwd"},
+		{"a/b", "a_b"},
+		{`a\b`, "a_b"},
+		{"..", ""},
+		{".hidden", "hidden"},
+		{"con:nul", "con_nul"},
+		{"name\x00with\nnul", "name_with_nul"},
+		{"émilie", "milie"},
+		{"日本", ""},
+		{strings.Repeat("x", 100), strings.Repeat("x", 64)},
+	}
+
+	for _, tt := range tests {
+		got := sanitizeFilename(tt.name)
+		if got != tt.want {
+			t.Errorf("sanitizeFilename(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+		if strings.ContainsAny(got, `/\`) || strings.HasPrefix(got, ".") {
+			t.Errorf("sanitizeFilename(%q) = %q is not a safe file name", tt.name, got)
+		}
+	}
+}
+
+func TestFriendFileNameFallsBack(t *testing.T) {
+	tests := []struct {
+		friend Friend
+		want   string
+	}{
+		{Friend{FriendID: "7", FriendUsername: "日本"}, "7"},
+		{Friend{FriendID: "../", FriendUsername: "/"}, "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := friendFileName(&tt.friend); got != tt.want {
+			t.Errorf("friendFileName(%+v) = %q, want %q", tt.friend, got, tt.want)
+		}
+	}
+}
+
+func TestExportFileNamesDisambiguatesCollisions(t *testing.T) {
+	friends := &FriendsData{Friends: []Friend{
+		{FriendID: "1", FriendUsername: "a/b"},
+		{FriendID: "2", FriendUsername: "a_b"},
+		{FriendID: "3", FriendUsername: "Alice"},
+		{FriendID: "4", FriendUsername: "alice"},
+		{FriendID: "5", FriendUsername: "a_b@1"},
+		{FriendID: "6", FriendUsername: "bob"},
+	}}
+
+	want := map[string]string{
+		"1": "a_b@1",
+		"2": "a_b@2",
+		"3": "Alice@3",
+		"4": "alice@4",
+		"5": "a_b_1",
+		"6": "bob",
+	}
+	got := exportFileNames(friends)
+
+	seen := make(map[string]string)
+	for id, name := range got {
+		if name != want[id] {
+			t.Errorf("friend %s file name = %q, want %q", id, name, want[id])
+		}
+		folded := strings.ToLower(name)
+		if other, ok := seen[folded]; ok {
+			t.Errorf("friends %s and %s share the file name %q", other, id, name)
+		}
+		seen[folded] = id
+	}
+}
+
+func TestExportConversationVerifiesMessages(t *testing.T) {
+	newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, http.StatusOK, ConversationResponse{Conversation: []Message{
+			{MessageID: 1, Sender: "1", Recipient: "2", Message: "see below\n[10:00] alice: not a real message", Timestamp: "2026-01-01 10:00:00"},
+			{MessageID: 2, Sender: "2", Recipient: "1", Message: "carriage\r[return]", Timestamp: "2026-01-01 10:01:00"},
+			{MessageID: 3, Sender: "2", Recipient: "1", Message: "", Timestamp: "2026-01-01 10:02:00"},
+		}})
+	})
+
+	exportDir := t.TempDir()
+	token := &TokenData{Token: "tok", UserID: "1"}
+	friend := &Friend{FriendID: "2", FriendUsername: "alice\n[evil]"}
+
+	record, err := exportConversation(token, friend, exportDir, "conversation_alice.txt")
+	if err != nil {
+		t.Fatalf("exportConversation: %v", err)
+	}
+	if record.MessageCount != 3 {
+		t.Errorf("MessageCount = %d, want 3", record.MessageCount)
+	}
+
+	count, err := countExportedMessages(filepath.Join(exportDir, record.File))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("countExportedMessages = %d, want 3", count)
+	}
+	if !isExportComplete(exportDir, *record) {
+		t.Error("a verified export is not reported complete")
+	}
+}
+
+func TestWriteConversationTextCountsMessages(t *testing.T) {
+	var sb strings.Builder
+	messages := []Message{{Message: "a\nb"}, {Message: "c"}}
+	if written := writeConversationText(&sb, &TokenData{UserID: "1"}, &Friend{FriendUsername: "bob"}, messages); written != 2 {
+		t.Errorf("writeConversationText wrote %d messages, want 2", written)
+	}
+	if !strings.Contains(sb.String(), "a\n    b") {
+		t.Errorf("continuation line is not indented:\n%s", sb.String())
+	}
+}
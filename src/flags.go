@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Global options, set by parseGlobalFlags before a command runs
+var (
+	traceFile     string
+	passphrase    string
+	profile       string
+	minStatusCode = 200
+	dryRun        bool
+	debug         bool
+	extraHeaders  = make(map[string]string)
+)
+
+// parseGlobalFlags removes the global flags from args and applies them.
+// Flags may be written as "--name value" or "--name=value".
+func parseGlobalFlags(args []string) ([]string, error) {
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+
+		// takeValue returns the flag value, consuming the next argument if needed
+		takeValue := func() (string, error) {
+			if hasValue {
+				return value, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("flag %s requires a value", name)
+			}
+			i++
+			return args[i], nil
+		}
+
+		var err error
+		switch name {
+		case "--trace-file":
+			traceFile, err = takeValue()
+		case "--passphrase":
+			passphrase, err = takeValue()
+		case "--profile":
+			profile, err = takeValue()
+		case "--log-level":
+			var level string
+			level, err = takeValue()
+			if err == nil {
+				err = setupLogging(level)
+			}
+		case "--min-status-code":
+			var code string
+			code, err = takeValue()
+			if err == nil {
+				err = setMinStatusCode(code)
+			}
+		case "--dry-run":
+			dryRun = true
+		case "--no-color":
+			colorEnabled = false
+		case "--json":
+			jsonOutput = true
+		case "--quiet":
+			quiet = true
+		case "--debug":
+			debug = true
+		case "--header":
+			var header string
+			header, err = takeValue()
+			if err == nil {
+				err = addExtraHeader(header)
+			}
+		default:
+			rest = append(rest, args[i])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rest, nil
+}
+
+// setMinStatusCode sets the lowest status code --min-status-code treats as success.
+// It must be a 2xx code, since anything outside 2xx is never a success.
+func setMinStatusCode(value string) error {
+	code, err := strconv.Atoi(value)
+	if err != nil || code < 200 || code > 299 {
+		return fmt.Errorf("invalid --min-status-code %q: must be a number from 200 to 299", value)
+	}
+	minStatusCode = code
+	return nil
+}
+
+// addExtraHeader records a "Key: Value" header given with --header
+func addExtraHeader(header string) error {
+	key, value, ok := strings.Cut(header, ":")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return fmt.Errorf("invalid header %q: expected \"Key: Value\"", header)
+	}
+	extraHeaders[key] = strings.TrimSpace(value)
+	return nil
+}
+
+// hasFlag reports whether a boolean command flag such as "--watch" appears in args
+func hasFlag(args []string, name string) bool {
+	for _, arg := range args {
+		if arg == name {
+			return true
+		}
+	}
+	return false
+}
+
+// flagValue returns the value of a "--name value" or "--name=value" command flag in args
+func flagValue(args []string, name string) (string, bool) {
+	for i, arg := range args {
+		if arg == name && i+1 < len(args) {
+			return args[i+1], true
+		}
+		if value, ok := strings.CutPrefix(arg, name+"="); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// firstArg returns the first argument in args that is not a flag, skipping the
+// values of the "--name value" flags listed in valueFlags
+func firstArg(args []string, valueFlags ...string) string {
+	for i := 0; i < len(args); i++ {
+		if !strings.HasPrefix(args[i], "--") {
+			return args[i]
+		}
+		if slices.Contains(valueFlags, args[i]) {
+			i++
+		}
+	}
+	return ""
+}
+
+// parseInterval parses a duration such as "10s" or "1m"; a bare number is taken as seconds
+func parseInterval(value string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid interval %q: use seconds or a duration like 10s", value)
+	}
+	return interval, nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// FriendInfo is everything known about one friendship, as printed by friend-info
+type FriendInfo struct {
+	Username         string `json:"username"`
+	UserID           string `json:"user_id"`
+	FriendshipDate   string `json:"friendship_date"`
+	FriendshipID     int    `json:"friendship_id"`
+	TotalMessages    int    `json:"total_messages"`
+	SentMessages     int    `json:"sent_messages"`
+	ReceivedMessages int    `json:"received_messages"`
+	UnreadMessages   int    `json:"unread_messages"`
+	LastMessageAt    string `json:"last_message_at,omitempty"`
+}
+
+// friendInfo implements the friend-info command: it shows the friendship details
+// of the friend named in the arguments, or picked from the list, with statistics
+// about your conversation
+func friendInfo() error {
+	args := os.Args[2:]
+	token, err := requireToken()
+	if err != nil {
+		return err
+	}
+
+	friends, err := loadFriends(token.Token, hasFlag(args, "--offline"))
+	if err != nil {
+		return fmt.Errorf("error fetching friends: %v", err)
+	}
+	if len(friends.Friends) == 0 {
+		return fmt.Errorf("no friends found in your friends list")
+	}
+
+	var friend *Friend
+	if name := firstArg(args); name != "" {
+		friend, err = friendByName(friends, name)
+	} else {
+		friend, err = selectFriendWithPrompt(friends, "Enter the number of the friend to show: ")
+	}
+	if errors.Is(err, errSelectionCancelled) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error selecting friend: %v", err)
+	}
+
+	conversation, err := getConversation(token, friend)
+	if err != nil {
+		return fmt.Errorf("error fetching conversation: %v", err)
+	}
+
+	info := FriendInfo{
+		Username:       friend.GetUsername(),
+		UserID:         friend.GetUserID(),
+		FriendshipDate: friend.Added(),
+		FriendshipID:   friend.FriendshipID,
+	}
+	messages := filterConversation(token, friend, conversation)
+	info.TotalMessages = len(messages)
+	for _, msg := range messages {
+		switch {
+		case msg.Sender == token.UserID:
+			info.SentMessages++
+		case !msg.IsRead:
+			info.ReceivedMessages++
+			info.UnreadMessages++
+		default:
+			info.ReceivedMessages++
+		}
+	}
+	if len(messages) > 0 {
+		info.LastMessageAt = messages[len(messages)-1].Timestamp
+	}
+
+	if jsonOutput {
+		return printJSON(info)
+	}
+	displayFriendInfo(info, friend)
+	return nil
+}
+
+// displayFriendInfo prints info as a profile card
+func displayFriendInfo(info FriendInfo, friend *Friend) {
+	friendshipDate := info.FriendshipDate
+	if friendshipDate == "" {
+		friendshipDate = "Unknown"
+	}
+
+	fmt.Printf("\n=== %s ===\n", friendDisplayName(friend))
+	fmt.Printf("User ID:        %s\n", info.UserID)
+	fmt.Printf("Friends since:  %s\n", friendshipDate)
+	if info.FriendshipID != 0 {
+		fmt.Printf("Friendship ID:  %d\n", info.FriendshipID)
+	} else {
+		fmt.Printf("Friendship ID:  Unknown%s\n", unsyncedNote(friend))
+	}
+	fmt.Println(strings.Repeat("-", 40))
+
+	if info.TotalMessages == 0 {
+		fmt.Println("No messages exchanged yet.")
+		return
+	}
+	fmt.Printf("Messages:       %d (%d sent, %d received)\n", info.TotalMessages, info.SentMessages, info.ReceivedMessages)
+	fmt.Printf("Unread:         %d\n", info.UnreadMessages)
+	fmt.Printf("Last message:   %s\n", info.LastMessageAt)
+}
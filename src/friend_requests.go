@@ -1,34 +1,49 @@
 package main
 
 import (
-	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
-	"syscall"
-	"unsafe"
+	"time"
+)
+
+const (
+	defaultRefreshInterval = 10 * time.Second
+	minRefreshInterval     = 2 * time.Second
 )
 
 // manageFriendRequests is the main function that handles friend request management
 func manageFriendRequests() error {
 	// Read token from config file
-	token, err := readTokenForFriendRequests()
+	token, err := requireToken()
 	if err != nil {
-		fmt.Printf("Error reading token: %v\n", err)
-		os.Exit(1)
+		return err
+	}
+
+	// In JSON mode print both request lists instead of the interactive menu
+	if jsonOutput {
+		return printFriendRequestsJSON(token)
+	}
+
+	// Watch mode keeps polling incoming requests instead of showing the menu
+	if hasFlag(os.Args[2:], "--watch") {
+		return watchIncomingRequests(token, os.Args[2:])
+	}
+
+	// Accept every respondable request from one sender in a single step
+	if sender, ok := flagValue(os.Args[2:], "--accept-all-from"); ok {
+		return acceptAllFrom(token, sender)
 	}
 
 	// Display menu and get user choice
 	choice, err := displayFriendRequestMenu()
 	if err != nil {
-		fmt.Printf("Error getting user choice: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("error getting user choice: %v", err)
 	}
 
 	// Handle user choice
@@ -38,45 +53,32 @@ func manageFriendRequests() error {
 	case 2:
 		err = handleOutgoingRequests(token)
 	default:
-		fmt.Println("Invalid choice. Please try again.")
+		errorf("Invalid choice. Please try again.\n")
 		return manageFriendRequests()
 	}
 
 	if err != nil {
-		fmt.Printf("Error handling friend requests: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("error handling friend requests: %v", err)
 	}
 
 	return nil
 }
 
-// readTokenForFriendRequests reads the token from ~/.config/chat_app/token.json
-func readTokenForFriendRequests() (*TokenData, error) {
-	homeDir, err := os.UserHomeDir()
+// printFriendRequestsJSON prints the incoming and outgoing request responses as JSON
+func printFriendRequestsJSON(token *TokenData) error {
+	incoming, err := fetchIncomingFriendRequests(token, apiURL("/auth/get_incoming_friend_requests"))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %v", err)
-	}
-
-	tokenPath := filepath.Join(homeDir, ".config", "chat_app", "token.json")
-	
-	file, err := os.Open(tokenPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open token file: %v", err)
-	}
-	defer file.Close()
-
-	data, err := io.ReadAll(file)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read token file: %v", err)
+		return fmt.Errorf("failed to fetch incoming requests: %v", err)
 	}
-
-	var tokenData TokenData
-	err = json.Unmarshal(data, &tokenData)
+	outgoing, err := fetchOutgoingFriendRequests(token, apiURL("/auth/get_outgoing_friend_requests"))
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse token file: %v", err)
+		return fmt.Errorf("failed to fetch outgoing requests: %v", err)
 	}
 
-	return &tokenData, nil
+	return printJSON(struct {
+		Incoming *IncomingFriendRequestsResponse `json:"incoming"`
+		Outgoing *OutgoingFriendRequestsResponse `json:"outgoing"`
+	}{incoming, outgoing})
 }
 
 // displayFriendRequestMenu displays the menu and returns user choice
@@ -86,8 +88,7 @@ func displayFriendRequestMenu() (int, error) {
 	fmt.Println("2. View Outgoing Friend Requests")
 	fmt.Print("\nEnter your choice (1 or 2): ")
 
-	var choice string
-	fmt.Scanln(&choice)
+	choice, _ := readLine()
 
 	choiceNum, err := strconv.Atoi(choice)
 	if err != nil {
@@ -105,7 +106,7 @@ func displayFriendRequestMenu() (int, error) {
 func handleIncomingRequests(token *TokenData) error {
 	fmt.Println("\n📥 Fetching incoming friend requests...")
 	
-	url := "https://wasalbackend-production.up.railway.app/auth/get_incoming_friend_requests"
+	url := apiURL("/auth/get_incoming_friend_requests")
 	
 	requests, err := fetchIncomingFriendRequests(token, url)
 	if err != nil {
@@ -116,16 +117,14 @@ func handleIncomingRequests(token *TokenData) error {
 	
 	// Wait for CTRL+R input
 	fmt.Println("\nPress CTRL+R to respond to friend requests or CTRL+C to exit...")
-	waitForCtrlR(token, requests.IncomingRequests)
-	
-	return nil
+	return waitForCtrlR(token, requests.IncomingRequests)
 }
 
 // handleOutgoingRequests fetches and displays outgoing friend requests
 func handleOutgoingRequests(token *TokenData) error {
 	fmt.Println("\n📤 Fetching outgoing friend requests...")
 	
-	url := "https://wasalbackend-production.up.railway.app/auth/get_outgoing_friend_requests"
+	url := apiURL("/auth/get_outgoing_friend_requests")
 	
 	requests, err := fetchOutgoingFriendRequests(token, url)
 	if err != nil {
@@ -133,42 +132,115 @@ func handleOutgoingRequests(token *TokenData) error {
 	}
 
 	displayOutgoingFriendRequests(requests)
-	return nil
+
+	// Wait for CTRL+R input
+	fmt.Println("\nPress CTRL+R to cancel a pending friend request or CTRL+C to exit...")
+	return waitForCtrlRThen(func() {
+		handleCancelRequest(token, requests.OutgoingRequests)
+	})
 }
 
-// waitForCtrlR waits for CTRL+R key combination
-func waitForCtrlR(token *TokenData, requests []IncomingFriendRequest) {
-	// Set terminal to raw mode to capture key combinations
-	oldState, err := makeRaw(int(os.Stdin.Fd()))
-	if err != nil {
-		fmt.Printf("Error setting terminal to raw mode: %v\n", err)
-		return
+// watchIncomingRequests polls incoming friend requests and prints new ones as they arrive
+func watchIncomingRequests(token *TokenData, args []string) error {
+	interval := defaultRefreshInterval
+	if value, ok := flagValue(args, "--refresh-interval"); ok {
+		parsed, err := parseInterval(value)
+		if err != nil {
+			return err
+		}
+		interval = parsed
 	}
-	defer restore(int(os.Stdin.Fd()), oldState)
+	if interval < minRefreshInterval {
+		errorf("Refresh interval raised to the minimum of %s\n", minRefreshInterval)
+		interval = minRefreshInterval
+	}
+	verbose := hasFlag(args, "--verbose")
+
+	url := apiURL("/auth/get_incoming_friend_requests")
 
-	buffer := make([]byte, 1)
+	fmt.Printf("👀 Watching incoming friend requests every %s (CTRL+C to stop)...\n", interval)
+
+	seen := make(map[int]bool)
 	for {
-		n, err := os.Stdin.Read(buffer)
+		requests, err := fetchIncomingFriendRequests(token, url)
 		if err != nil {
-			fmt.Printf("Error reading input: %v\n", err)
-			return
-		}
-		
-		if n > 0 {
-			// Check for CTRL+R (ASCII 18)
-			if buffer[0] == 18 {
-				// Restore terminal before showing menu
-				restore(int(os.Stdin.Fd()), oldState)
-				handleFriendRequestResponse(token, requests)
-				return
-			}
-			// Check for CTRL+C (ASCII 3)
-			if buffer[0] == 3 {
-				fmt.Println("\nExiting...")
-				os.Exit(0)
+			errorf("Error fetching incoming requests: %v\n", err)
+		} else {
+			for _, request := range requests.IncomingRequests {
+				if seen[request.RequestID] {
+					continue
+				}
+				seen[request.RequestID] = true
+				fmt.Printf("[%s] 📥 Request from %s (Status: %s, Request ID: %d)\n",
+					time.Now().Format("15:04:05"), request.SenderUsername, request.Status, request.RequestID)
 			}
 		}
+
+		waitForNextRefresh(interval, verbose)
+	}
+}
+
+// waitForNextRefresh sleeps for interval, showing a countdown in verbose mode
+func waitForNextRefresh(interval time.Duration, verbose bool) {
+	if !verbose {
+		time.Sleep(interval)
+		return
+	}
+
+	for remaining := interval; remaining > 0; remaining -= time.Second {
+		fmt.Printf("\rNext refresh in %ds ", int(remaining.Round(time.Second).Seconds()))
+		time.Sleep(min(time.Second, remaining))
 	}
+	fmt.Print("\r\033[K")
+}
+
+// waitForCtrlR waits for CTRL+R key combination
+func waitForCtrlR(token *TokenData, requests []IncomingFriendRequest) error {
+	return waitForCtrlRThen(func() {
+		handleFriendRequestResponse(token, requests)
+	})
+}
+
+// waitForCtrlRThen waits for CTRL+R and runs action with the terminal restored.
+// CTRL+C returns without running action. Errors are returned rather than exiting
+// here, so the deferred terminal restore always runs.
+func waitForCtrlRThen(action func()) error {
+	// Set terminal to raw mode to capture key combinations; where that is not
+	// possible fall back to pressing Enter
+	oldState, err := makeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		debugf("Raw mode unavailable: %v\n", err)
+		return waitForEnterThen(action)
+	}
+	// Deferred so the terminal is restored even if a handler panics
+	defer restore(int(os.Stdin.Fd()), oldState)
+
+	return dispatchKeys(os.Stdin, map[byte]func() bool{
+		18: func() bool { // CTRL+R
+			// Restore terminal before showing menu
+			restore(int(os.Stdin.Fd()), oldState)
+			action()
+			return false
+		},
+		3: func() bool { // CTRL+C
+			restore(int(os.Stdin.Fd()), oldState)
+			fmt.Println("\nExiting...")
+			return false
+		},
+	})
+}
+
+// waitForEnterThen is the fallback for waitForCtrlRThen when raw mode is not
+// available: Enter runs action, while "q" or end of input returns without it
+func waitForEnterThen(action func()) error {
+	fmt.Print("Press Enter to continue, or q to quit: ")
+	input, err := readLine()
+	if (err != nil && input == "") || strings.EqualFold(input, "q") {
+		fmt.Println("Exiting...")
+		return nil
+	}
+	action()
+	return nil
 }
 
 // handleFriendRequestResponse handles the friend request response flow
@@ -176,7 +248,7 @@ func handleFriendRequestResponse(token *TokenData, requests []IncomingFriendRequ
 	// Filter requests that can be responded to (pending or rejected status)
 	var respondableRequests []IncomingFriendRequest
 	for _, request := range requests {
-		if strings.ToLower(request.Status) == "pending" || strings.ToLower(request.Status) == "rejected" {
+		if isRespondable(request) {
 			respondableRequests = append(respondableRequests, request)
 		}
 	}
@@ -196,17 +268,17 @@ func handleFriendRequestResponse(token *TokenData, requests []IncomingFriendRequ
 	}
 	
 	fmt.Print("\nEnter the number of the request to respond to: ")
-	reader := bufio.NewReader(os.Stdin)
+	reader := stdinReader
 	input, err := reader.ReadString('\n')
 	if err != nil {
-		fmt.Printf("Error reading input: %v\n", err)
+		errorf("Error reading input: %v\n", err)
 		return
 	}
 	
 	input = strings.TrimSpace(input)
 	requestIndex, err := strconv.Atoi(input)
 	if err != nil || requestIndex < 1 || requestIndex > len(respondableRequests) {
-		fmt.Println("Invalid request number.")
+		errorf("Invalid request number.\n")
 		return
 	}
 	
@@ -219,14 +291,14 @@ func handleFriendRequestResponse(token *TokenData, requests []IncomingFriendRequ
 	
 	actionInput, err := reader.ReadString('\n')
 	if err != nil {
-		fmt.Printf("Error reading input: %v\n", err)
+		errorf("Error reading input: %v\n", err)
 		return
 	}
 	
 	actionInput = strings.TrimSpace(actionInput)
 	actionChoice, err := strconv.Atoi(actionInput)
 	if err != nil || (actionChoice != 1 && actionChoice != 2) {
-		fmt.Println("Invalid choice.")
+		errorf("Invalid choice.\n")
 		return
 	}
 	
@@ -240,17 +312,174 @@ func handleFriendRequestResponse(token *TokenData, requests []IncomingFriendRequ
 	// Send the response to the API
 	err = respondToFriendRequest(token, selectedRequest.SenderUsername, action)
 	if err != nil {
-		fmt.Printf("Error responding to friend request: %v\n", err)
+		errorf("Error responding to friend request: %v\n", err)
 		return
 	}
 	
-	fmt.Printf("Successfully %sed friend request from %s!\n", action, selectedRequest.SenderUsername)
-	fmt.Println("Program will now exit.")
+	infof("Successfully %sed friend request from %s!\n", action, selectedRequest.SenderUsername)
+	infoln("Program will now exit.")
+}
+
+// handleCancelRequest lets the user pick a pending outgoing request and withdraw it
+func handleCancelRequest(token *TokenData, requests []OutgoingFriendRequest) {
+	// Only pending requests can be cancelled
+	var pendingRequests []OutgoingFriendRequest
+	for _, request := range requests {
+		if strings.EqualFold(request.Status, "pending") {
+			pendingRequests = append(pendingRequests, request)
+		}
+	}
+
+	if len(pendingRequests) == 0 {
+		fmt.Println("\nNo pending outgoing friend requests to cancel.")
+		return
+	}
+
+	fmt.Println("\n=== Cancel Friend Requests ===")
+	for i, request := range pendingRequests {
+		fmt.Printf("%d. To: %s (Request ID: %d)\n", i+1, request.RecipientUsername, request.RequestID)
+	}
+
+	fmt.Print("\nEnter the number of the request to cancel: ")
+	reader := stdinReader
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		errorf("Error reading input: %v\n", err)
+		return
+	}
+
+	requestIndex, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil || requestIndex < 1 || requestIndex > len(pendingRequests) {
+		errorf("Invalid request number.\n")
+		return
+	}
+
+	selectedRequest := pendingRequests[requestIndex-1]
+	if err := cancelFriendRequest(token, selectedRequest.RequestID); err != nil {
+		errorf("Error cancelling friend request: %v\n", err)
+		return
+	}
+	fmt.Printf("Cancelled friend request to %s.\n", selectedRequest.RecipientUsername)
+
+	// Refresh the list to confirm the request is gone
+	refreshed, err := fetchOutgoingFriendRequests(token, apiURL("/auth/get_outgoing_friend_requests"))
+	if err != nil {
+		errorf("Error refreshing outgoing requests: %v\n", err)
+		return
+	}
+	displayOutgoingFriendRequests(refreshed)
+}
+
+// cancelFriendRequest withdraws an outgoing friend request
+func cancelFriendRequest(token *TokenData, requestID int) error {
+	jsonData, err := json.Marshal(map[string]int{"request_id": requestID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request data: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(appContext(), "POST", apiURL("/auth/cancel_friend_request"), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := newHTTPClient()
+	resp, err := doRequest(client, req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %v", err)
+	}
+
+	if !isSuccess(resp.StatusCode) {
+		return newAPIError(resp, body)
+	}
+
+	return nil
+}
+
+// isRespondable reports whether a request can still be accepted or rejected (pending or rejected status)
+func isRespondable(request IncomingFriendRequest) bool {
+	status := strings.ToLower(request.Status)
+	return status == "pending" || status == "rejected"
+}
+
+// acceptAllFrom accepts all respondable incoming requests from the given sender after one confirmation
+func acceptAllFrom(token *TokenData, sender string) error {
+	url := apiURL("/auth/get_incoming_friend_requests")
+
+	requests, err := fetchIncomingFriendRequests(token, url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch incoming requests: %v", err)
+	}
+
+	var matching []IncomingFriendRequest
+	for _, request := range requests.IncomingRequests {
+		if strings.EqualFold(request.SenderUsername, sender) && isRespondable(request) {
+			matching = append(matching, request)
+		}
+	}
+
+	if len(matching) == 0 {
+		fmt.Printf("No pending or rejected friend requests from %s.\n", sender)
+		return nil
+	}
+
+	fmt.Printf("\nFound %d request(s) from %s:\n", len(matching), sender)
+	for i, request := range matching {
+		fmt.Printf("%d. Request ID: %d (Status: %s)\n", i+1, request.RequestID, request.Status)
+	}
+
+	fmt.Printf("\nAccept all %d request(s) from %s? [y/N]: ", len(matching), sender)
+	reader := stdinReader
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("error reading input: %v", err)
+	}
+	input = strings.ToLower(strings.TrimSpace(input))
+	if input != "y" && input != "yes" {
+		fmt.Println("No requests were accepted.")
+		return nil
+	}
+
+	// The API accepts by sender username, so one call covers all of a sender's
+	// requests; repeating it for duplicates would fail once the first succeeded
+	requestIDs := make(map[string][]string)
+	var senders []string
+	for _, request := range matching {
+		if _, ok := requestIDs[request.SenderUsername]; !ok {
+			senders = append(senders, request.SenderUsername)
+		}
+		requestIDs[request.SenderUsername] = append(requestIDs[request.SenderUsername], strconv.Itoa(request.RequestID))
+	}
+
+	accepted := 0
+	for _, username := range senders {
+		ids := strings.Join(requestIDs[username], ", ")
+		if err := respondToFriendRequest(token, username, "accept"); err != nil {
+			errorf("❌ Request ID %s: %v\n", ids, err)
+			continue
+		}
+		infof("✅ Request ID %s accepted\n", ids)
+		accepted += len(requestIDs[username])
+	}
+
+	infof("\nAccepted %d of %d request(s) from %s.\n", accepted, len(matching), sender)
+	if accepted < len(matching) {
+		return fmt.Errorf("%d request(s) could not be accepted", len(matching)-accepted)
+	}
+	return nil
 }
 
 // respondToFriendRequest sends the response to the friend request API
 func respondToFriendRequest(token *TokenData, username, action string) error {
-	url := "https://wasalbackend-production.up.railway.app/auth/respond_friend_request"
+	url := apiURL("/auth/respond_friend_request")
 	
 	requestData := map[string]string{
 		"username": username,
@@ -262,7 +491,7 @@ func respondToFriendRequest(token *TokenData, username, action string) error {
 		return fmt.Errorf("failed to marshal request data: %v", err)
 	}
 	
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(appContext(), "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %v", err)
 	}
@@ -270,8 +499,8 @@ func respondToFriendRequest(token *TokenData, username, action string) error {
 	req.Header.Set("Authorization", "Bearer "+token.Token)
 	req.Header.Set("Content-Type", "application/json")
 	
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	client := newHTTPClient()
+	resp, err := doRequest(client, req)
 	if err != nil {
 		return fmt.Errorf("failed to send request: %v", err)
 	}
@@ -282,60 +511,17 @@ func respondToFriendRequest(token *TokenData, username, action string) error {
 		return fmt.Errorf("failed to read response: %v", err)
 	}
 	
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	if !isSuccess(resp.StatusCode) {
+		return newAPIError(resp, body)
 	}
 	
 	return nil
 }
 
-// Terminal manipulation functions for Unix-like systems
-type termios struct {
-	Iflag  uint32
-	Oflag  uint32
-	Cflag  uint32
-	Lflag  uint32
-	Cc     [20]uint8
-	Ispeed uint32
-	Ospeed uint32
-}
-
-func makeRaw(fd int) (*termios, error) {
-	var oldState termios
-	
-	// Get current terminal settings
-	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), 0x5401, uintptr(unsafe.Pointer(&oldState)))
-	if errno != 0 {
-		return nil, errno
-	}
-	
-	// Create new settings for raw mode
-	newState := oldState
-	newState.Lflag &^= syscall.ICANON | syscall.ECHO
-	newState.Cc[syscall.VMIN] = 1
-	newState.Cc[syscall.VTIME] = 0
-	
-	// Apply new settings
-	_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), 0x5402, uintptr(unsafe.Pointer(&newState)))
-	if errno != 0 {
-		return nil, errno
-	}
-	
-	return &oldState, nil
-}
-
-func restore(fd int, oldState *termios) error {
-	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), 0x5402, uintptr(unsafe.Pointer(oldState)))
-	if errno != 0 {
-		return errno
-	}
-	return nil
-}
-
 // fetchIncomingFriendRequests makes HTTP request to fetch incoming friend requests
 func fetchIncomingFriendRequests(token *TokenData, url string) (*IncomingFriendRequestsResponse, error) {
 	// Create HTTP request
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(appContext(), "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %v", err)
 	}
@@ -344,8 +530,8 @@ func fetchIncomingFriendRequests(token *TokenData, url string) (*IncomingFriendR
 	req.Header.Set("Authorization", "Bearer "+token.Token)
 
 	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	client := newHTTPClient()
+	resp, err := doRequestWithRetry(client, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %v", err)
 	}
@@ -358,8 +544,8 @@ func fetchIncomingFriendRequests(token *TokenData, url string) (*IncomingFriendR
 	}
 
 	// Check if request was successful
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	if !isSuccess(resp.StatusCode) {
+		return nil, newAPIError(resp, body)
 	}
 
 	// Parse response
@@ -375,7 +561,7 @@ func fetchIncomingFriendRequests(token *TokenData, url string) (*IncomingFriendR
 // fetchOutgoingFriendRequests makes HTTP request to fetch outgoing friend requests
 func fetchOutgoingFriendRequests(token *TokenData, url string) (*OutgoingFriendRequestsResponse, error) {
 	// Create HTTP request
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(appContext(), "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %v", err)
 	}
@@ -384,8 +570,8 @@ func fetchOutgoingFriendRequests(token *TokenData, url string) (*OutgoingFriendR
 	req.Header.Set("Authorization", "Bearer "+token.Token)
 
 	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	client := newHTTPClient()
+	resp, err := doRequestWithRetry(client, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %v", err)
 	}
@@ -398,8 +584,8 @@ func fetchOutgoingFriendRequests(token *TokenData, url string) (*OutgoingFriendR
 	}
 
 	// Check if request was successful
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	if !isSuccess(resp.StatusCode) {
+		return nil, newAPIError(resp, body)
 	}
 
 	// Parse response
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAcceptAllFromRespondsOncePerSender(t *testing.T) {
+	var responses []string
+	newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/get_incoming_friend_requests":
+			writeJSON(t, w, http.StatusOK, IncomingFriendRequestsResponse{IncomingRequests: []IncomingFriendRequest{
+				{RequestID: 1, SenderUsername: "alice", Status: "pending"},
+				{RequestID: 2, SenderUsername: "alice", Status: "rejected"},
+				{RequestID: 3, SenderUsername: "bob", Status: "pending"},
+				{RequestID: 4, SenderUsername: "alice", Status: "accepted"},
+			}})
+		case "/auth/respond_friend_request":
+			var request map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				t.Errorf("decoding request: %v", err)
+			}
+			responses = append(responses, request["username"]+":"+request["action"])
+			writeJSON(t, w, http.StatusOK, map[string]string{"message": "ok"})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	saved := stdinReader
+	stdinReader = bufio.NewReader(strings.NewReader("y\n"))
+	t.Cleanup(func() { stdinReader = saved })
+
+	if err := acceptAllFrom(&TokenData{Token: "tok"}, "ALICE"); err != nil {
+		t.Fatalf("acceptAllFrom: %v", err)
+	}
+	if len(responses) != 1 || responses[0] != "alice:accept" {
+		t.Errorf("responses sent = %v, want one alice:accept", responses)
+	}
+}
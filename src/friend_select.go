@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxSelectAttempts is how many answers the friend picker accepts before giving up
+const maxSelectAttempts = 3
+
+// errSelectionCancelled is returned when the user cancels the friend picker
+// with a blank answer or "q"; commands treat it as a quiet exit, not a failure
+var errSelectionCancelled = errors.New("no friend selected")
+
+// promptForFriend asks with prompt until the answer names a friend, showing the
+// valid range after an invalid answer and giving up after maxSelectAttempts.
+// A blank answer, "q" or end of input cancels with errSelectionCancelled.
+func promptForFriend(friends *FriendsData, prompt string) (*Friend, error) {
+	for attempt := 1; ; attempt++ {
+		promptf("\n%s", prompt)
+		choice, _ := readLine()
+		if choice == "" || strings.EqualFold(choice, "q") {
+			promptf("Cancelled.\n")
+			return nil, errSelectionCancelled
+		}
+
+		friend, err := chooseFriend(friends, choice)
+		if err == nil {
+			return friend, nil
+		}
+		if attempt == maxSelectAttempts {
+			return nil, err
+		}
+		promptf("%v\nEnter a number from 1 to %d or a username, or q to cancel.\n", err, len(friends.Friends))
+	}
+}
+
+// chooseFriend resolves the user's answer to a friend picker. The answer may be a
+// list number, an alias or a username; a username is matched fuzzily, a single match is
+// selected and when several friends match the user picks from the narrowed list by number.
+func chooseFriend(friends *FriendsData, choice string) (*Friend, error) {
+	if choiceNum, err := strconv.Atoi(choice); err == nil {
+		if choiceNum < 1 || choiceNum > len(friends.Friends) {
+			return nil, fmt.Errorf("invalid choice: please select a number between 1 and %d", len(friends.Friends))
+		}
+		return &friends.Friends[choiceNum-1], nil
+	}
+
+	if choice == "" {
+		return nil, fmt.Errorf("invalid choice: please enter a number or a username")
+	}
+
+	if friend := friendByAlias(friends, choice); friend != nil {
+		return friend, nil
+	}
+
+	matches := matchFriends(friends, choice)
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("invalid choice: no friend matches %q", choice)
+	case 1:
+		return matches[0], nil
+	}
+
+	promptf("\n%q matches several friends:\n", choice)
+	for i, friend := range matches {
+		promptf("%d. %s (ID: %s)\n", i+1, friend.GetUsername(), friend.GetUserID())
+	}
+	promptf("\nEnter the number of the friend: ")
+	input, _ := readLine()
+
+	choiceNum, err := strconv.Atoi(input)
+	if err != nil || choiceNum < 1 || choiceNum > len(matches) {
+		return nil, fmt.Errorf("invalid choice: please select a number between 1 and %d", len(matches))
+	}
+	return matches[choiceNum-1], nil
+}
+
+// matchFriends returns the friends whose username fuzzily matches input, best match
+// first. The letters of input must appear in order in the username, ignoring case,
+// so "jo" matches both "john_doe" and "jolene". An exact username match is returned on its own.
+func matchFriends(friends *FriendsData, input string) []*Friend {
+	type scoredFriend struct {
+		friend *Friend
+		score  int
+	}
+
+	var scored []scoredFriend
+	for i := range friends.Friends {
+		friend := &friends.Friends[i]
+		if strings.EqualFold(friend.GetUsername(), input) {
+			return []*Friend{friend}
+		}
+		if score := fuzzyScore(friend.GetUsername(), input); score >= 0 {
+			scored = append(scored, scoredFriend{friend, score})
+		}
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+
+	matches := make([]*Friend, len(scored))
+	for i, match := range scored {
+		matches[i] = match.friend
+	}
+	return matches
+}
+
+// fuzzyScore scores how well pattern matches candidate as a case-insensitive
+// subsequence, or returns -1 when it does not match. Matches at the start,
+// after a separator and in consecutive runs score higher; gaps score lower.
+func fuzzyScore(candidate, pattern string) int {
+	text := []rune(strings.ToLower(candidate))
+	wanted := []rune(strings.ToLower(pattern))
+
+	score := 0
+	last := -1
+	next := 0
+	for i, r := range text {
+		if next == len(wanted) {
+			break
+		}
+		if r != wanted[next] {
+			continue
+		}
+
+		score += 1
+		switch {
+		case i == 0:
+			score += 8
+		case strings.ContainsRune("_-. ", text[i-1]):
+			score += 5
+		}
+		if last >= 0 {
+			if i == last+1 {
+				score += 4
+			} else {
+				score -= min(i-last-1, 3)
+			}
+		}
+		last = i
+		next++
+	}
+
+	if next < len(wanted) {
+		return -1
+	}
+	return max(score, 0)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		candidate, pattern string
+		match              bool
+	}{
+		{"john_doe", "jo", true},
+		{"john_doe", "jd", true},
+		{"john_doe", "JD", true},
+		{"john_doe", "dj", false},
+		{"john_doe", "johnx", false},
+		{"alice", "", true},
+		{"", "a", false},
+	}
+
+	for _, tt := range tests {
+		if got := fuzzyScore(tt.candidate, tt.pattern); (got >= 0) != tt.match {
+			t.Errorf("fuzzyScore(%q, %q) = %d, want match=%v", tt.candidate, tt.pattern, got, tt.match)
+		}
+	}
+}
+
+func TestFuzzyScoreRanking(t *testing.T) {
+	tests := []struct {
+		pattern, better, worse string
+	}{
+		{"jo", "jolene", "major"},       // prefix beats a match later on
+		{"jd", "john_doe", "jxxxxxxd"},  // after a separator beats a gap
+		{"ali", "alice", "amelia"},      // consecutive run beats scattered letters
+		{"doe", "doe_john", "john_doe"}, // start of the name beats after a separator
+	}
+
+	for _, tt := range tests {
+		better, worse := fuzzyScore(tt.better, tt.pattern), fuzzyScore(tt.worse, tt.pattern)
+		if better <= worse {
+			t.Errorf("pattern %q: %q scored %d, not above %q with %d", tt.pattern, tt.better, better, tt.worse, worse)
+		}
+	}
+}
+
+func TestMatchFriends(t *testing.T) {
+	friends := &FriendsData{Friends: []Friend{
+		{FriendID: "1", FriendUsername: "major"},
+		{FriendID: "2", FriendUsername: "jolene"},
+		{FriendID: "3", FriendUsername: "john_doe"},
+		{FriendID: "4", FriendUsername: "bob"},
+		{FriendID: "5", FriendUsername: "jo"},
+	}}
+
+	usernames := func(matches []*Friend) []string {
+		var names []string
+		for _, friend := range matches {
+			names = append(names, friend.GetUsername())
+		}
+		return names
+	}
+
+	if got := usernames(matchFriends(friends, "JO")); !reflect.DeepEqual(got, []string{"jo"}) {
+		t.Errorf("exact match: got %v, want only jo", got)
+	}
+	if got := usernames(matchFriends(friends, "jn")); !reflect.DeepEqual(got, []string{"john_doe", "jolene"}) {
+		t.Errorf("fuzzy match: got %v, want [john_doe jolene]", got)
+	}
+	if got := matchFriends(friends, "zz"); len(got) != 0 {
+		t.Errorf("no match: got %v", usernames(got))
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Friend list orders accepted by --sort; without it friends keep the API's order
+const (
+	sortByName   = "name"
+	sortByDate   = "date"
+	sortByRecent = "recent"
+)
+
+// friendDateLayouts are the formats the API has used for friendship dates
+var friendDateLayouts = []string{time.RFC3339, time.RFC1123, "2006-01-02 15:04:05", "2006-01-02"}
+
+// applyFriendSort orders friends as requested by the --sort flag in args:
+// "name" alphabetically, "date" most recently added first and "recent" by the
+// latest message exchanged. Friends without a usable date or any messages go last.
+func applyFriendSort(token *TokenData, friends []Friend, args []string) error {
+	order, ok := flagValue(args, "--sort")
+	if !ok {
+		return nil
+	}
+
+	switch order {
+	case sortByName:
+		slices.SortStableFunc(friends, func(a, b Friend) int {
+			return cmp.Compare(strings.ToLower(a.GetUsername()), strings.ToLower(b.GetUsername()))
+		})
+	case sortByDate:
+		sortFriendsByTime(friends, func(friend *Friend) (time.Time, bool) {
+			return parseFriendDate(friend.Added())
+		})
+	case sortByRecent:
+		lastMessages := lastMessageTimes(token, friends)
+		sortFriendsByTime(friends, func(friend *Friend) (time.Time, bool) {
+			last, ok := lastMessages[friend.GetUserID()]
+			return last, ok
+		})
+	default:
+		return fmt.Errorf("invalid --sort %q: use %s, %s or %s", order, sortByName, sortByDate, sortByRecent)
+	}
+	return nil
+}
+
+// sortFriendsByTime orders friends newest first by the time timeOf returns,
+// placing friends without one last in their original order
+func sortFriendsByTime(friends []Friend, timeOf func(*Friend) (time.Time, bool)) {
+	slices.SortStableFunc(friends, func(a, b Friend) int {
+		timeA, okA := timeOf(&a)
+		timeB, okB := timeOf(&b)
+		switch {
+		case okA && okB:
+			return timeB.Compare(timeA)
+		case okA:
+			return -1
+		case okB:
+			return 1
+		}
+		return 0
+	})
+}
+
+// parseFriendDate parses a friendship date in any of friendDateLayouts
+func parseFriendDate(value string) (time.Time, bool) {
+	for _, layout := range friendDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// lastMessageTimes fetches the conversation with every friend and returns the time
+// of the latest message in each, keyed by friend ID. Friends without messages are left out.
+func lastMessageTimes(token *TokenData, friends []Friend) map[string]time.Time {
+	conversations := fetchAllConversations(token, &FriendsData{Friends: friends})
+
+	lastMessages := make(map[string]time.Time)
+	for i := range friends {
+		conversation, ok := conversations[friends[i].GetUserID()]
+		if !ok {
+			continue
+		}
+		messages := filterConversation(token, &friends[i], conversation)
+		if len(messages) == 0 {
+			continue
+		}
+		if last, err := parseMessageTime(messages[len(messages)-1].Timestamp); err == nil {
+			lastMessages[friends[i].GetUserID()] = last
+		}
+	}
+	return lastMessages
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// listFriends implements the friends command
+func listFriends() error {
+	token, err := requireToken()
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		response, err := NewAPIClient(token.Token).GetFriendsResponse()
+		if err != nil {
+			return fmt.Errorf("error fetching friends: %v", err)
+		}
+		if err := applyFriendSort(token, response.Friends, os.Args[2:]); err != nil {
+			return err
+		}
+		return printJSON(response)
+	}
+
+	friends, err := fetchFriendsFromAPI(token.Token)
+	if err != nil {
+		return fmt.Errorf("error fetching friends: %v", err)
+	}
+	if err := applyFriendSort(token, friends.Friends, os.Args[2:]); err != nil {
+		return err
+	}
+
+	displayFriends(friends)
+	return nil
+}
+
+// displayFriends prints the friends list as a numbered table
+func displayFriends(friends *FriendsData) {
+	if len(friends.Friends) == 0 {
+		fmt.Println("You have no friends yet, use `search` to add some.")
+		return
+	}
+
+	fmt.Println("\n=== Your Friends ===")
+	fmt.Printf("%-4s %-24s %-38s %s\n", "#", "Username", "ID", "Friends since")
+	fmt.Println(strings.Repeat("-", 90))
+	for i, friend := range friends.Friends {
+		added := friend.Added()
+		if added == "" {
+			added = "Unknown"
+		}
+		fmt.Printf("%-4d %-24s %-38s %s%s\n", i+1, friend.GetUsername(), friend.GetUserID(), added, unsyncedNote(&friend))
+	}
+	fmt.Println(strings.Repeat("-", 90))
+	fmt.Printf("Total friends: %d\n", len(friends.Friends))
+}
+
+// refreshFriends implements the refresh-friends command: it fetches the friends list,
+// merges it with friends added locally and rewrites friends.json with the union
+func refreshFriends() error {
+	token, err := requireToken()
+	if err != nil {
+		return err
+	}
+
+	friends, err := fetchFriendsFromAPI(token.Token)
+	if err != nil {
+		return fmt.Errorf("error fetching friends: %v", err)
+	}
+
+	if jsonOutput {
+		return printJSON(friends)
+	}
+
+	unsynced := 0
+	for _, friend := range friends.Friends {
+		if friend.Unsynced {
+			unsynced++
+		}
+	}
+	fmt.Printf("Friends list refreshed: %d friends", len(friends.Friends))
+	if unsynced > 0 {
+		fmt.Printf(", %d added locally and not yet synced", unsynced)
+	}
+	fmt.Println()
+	return nil
+}
+
+// mergeLocalFriends adds the friends from the cached friends.json that are missing
+// from the API list and were added locally, marking them Unsynced. Cached entries
+// that came from the API (they carry a friendship ID) are dropped when the server
+// no longer lists them, so removed friends do not linger.
+func mergeLocalFriends(apiFriends *FriendsData) *FriendsData {
+	cached, err := readFriendsForReceiveMessage()
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			errorf("Warning: could not read cached friends list: %v\n", err)
+		}
+		return apiFriends
+	}
+
+	known := make(map[string]bool, len(apiFriends.Friends))
+	for _, friend := range apiFriends.Friends {
+		known[friend.GetUserID()] = true
+	}
+
+	merged := &FriendsData{Friends: apiFriends.Friends}
+	for _, friend := range cached.Friends {
+		userID := friend.GetUserID()
+		if userID == "" || known[userID] || friend.FriendshipID != 0 {
+			continue
+		}
+		friend.Unsynced = true
+		merged.Friends = append(merged.Friends, friend)
+		known[userID] = true
+	}
+	return merged
+}
+
+// unsyncedNote is the marker shown next to friends the server does not list yet
+func unsyncedNote(friend *Friend) string {
+	if !friend.Unsynced {
+		return ""
+	}
+	return colorize(ansiDim, " (not synced)")
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// historyFormats maps each supported --format to its file extension
+var historyFormats = map[string]string{
+	"text": "txt",
+	"json": "json",
+	"csv":  "csv",
+}
+
+// saveHistory implements the history command: it saves the conversation with
+// one friend to a file instead of opening the interactive view
+func saveHistory() error {
+	args := os.Args[2:]
+
+	format := "text"
+	if value, ok := flagValue(args, "--format"); ok {
+		format = strings.ToLower(value)
+	}
+	if _, ok := historyFormats[format]; !ok {
+		return fmt.Errorf("unsupported --format %q: use text, json or csv", format)
+	}
+
+	token, err := requireToken()
+	if err != nil {
+		return err
+	}
+
+	friends, err := loadFriends(token.Token, hasFlag(args, "--offline"))
+	if err != nil {
+		return fmt.Errorf("error fetching friends: %v", err)
+	}
+	if len(friends.Friends) == 0 {
+		return fmt.Errorf("no friends found in your friends list")
+	}
+
+	friend, err := selectFriendWithPrompt(friends, "Enter the number of the friend whose history you want to save: ")
+	if errors.Is(err, errSelectionCancelled) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	conversation, err := getConversation(token, friend)
+	if err != nil {
+		return err
+	}
+	messages := filterConversation(token, friend, conversation)
+
+	data, err := renderHistory(format, token, friend, messages)
+	if err != nil {
+		return err
+	}
+
+	path, ok := flagValue(args, "--out")
+	if !ok {
+		exportDir, err := exportsDir()
+		if err != nil {
+			return err
+		}
+		fileName := fmt.Sprintf("%s-%s.%s", exportFileNames(friends)[friend.GetUserID()], time.Now().Format("2006-01-02"), historyFormats[format])
+		path = filepath.Join(exportDir, fileName)
+	}
+
+	if err := writeFileAtomic(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write history: %v", err)
+	}
+
+	fmt.Printf("Saved %d messages with %s to %s\n", len(messages), friend.GetUsername(), path)
+	return nil
+}
+
+// renderHistory encodes messages in the given history format
+func renderHistory(format string, token *TokenData, friend *Friend, messages []Message) ([]byte, error) {
+	switch format {
+	case "json":
+		if messages == nil {
+			messages = []Message{}
+		}
+		jsonData, err := json.MarshalIndent(messages, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode history: %v", err)
+		}
+		return append(jsonData, '\n'), nil
+	case "csv":
+		return renderHistoryCSV(token, messages)
+	default:
+		var sb strings.Builder
+		writeConversationText(&sb, token, friend, messages)
+		return []byte(sb.String()), nil
+	}
+}
+
+// renderHistoryCSV writes one row per message for spreadsheet analysis
+func renderHistoryCSV(token *TokenData, messages []Message) ([]byte, error) {
+	var sb strings.Builder
+	writer := csv.NewWriter(&sb)
+
+	writer.Write([]string{"timestamp", "direction", "sender", "recipient", "message_id", "is_read", "message"})
+	for _, msg := range messages {
+		direction := "received"
+		if msg.Sender == token.UserID {
+			direction = "sent"
+		}
+		writer.Write([]string{
+			msg.Timestamp,
+			direction,
+			msg.Sender,
+			msg.Recipient,
+			strconv.Itoa(msg.MessageID),
+			strconv.FormatBool(msg.IsRead),
+			msg.Message,
+		})
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to encode history: %v", err)
+	}
+	return []byte(sb.String()), nil
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultHTTPTimeout bounds every API request unless config sets http_timeout
+const defaultHTTPTimeout = 20 * time.Second
+
+// retryDelays are the waits before each retry in doRequestWithRetry
+var retryDelays = []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second}
+
+// maxRetryAfter is the longest Retry-After wait that is waited out automatically
+const maxRetryAfter = time.Minute
+
+// APIError is returned when the API responds with a non-success status
+type APIError struct {
+	StatusCode int
+	Body       string
+	// RetryAfter is the wait requested by a 429 response's Retry-After header, if any
+	RetryAfter time.Duration
+}
+
+// newAPIError builds the APIError for a non-success response with the given body
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+		RetryAfter: retryAfter(resp.Header),
+	}
+}
+
+func (e *APIError) Error() string {
+	if e.StatusCode == http.StatusTooManyRequests {
+		if e.RetryAfter > 0 {
+			return fmt.Sprintf("rate limited, try again in %s", e.RetryAfter.Round(time.Second))
+		}
+		return "rate limited, try again later"
+	}
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date,
+// returning 0 when it is missing or invalid
+func retryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
+}
+
+// isAPIStatus reports whether err is an APIError with the given status code
+func isAPIStatus(err error, statusCode int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
+
+// newHTTPClient returns the client used for API requests, with the timeout from
+// the config file's http_timeout or defaultHTTPTimeout
+func newHTTPClient() *http.Client {
+	timeout := defaultHTTPTimeout
+	if value := loadConfig().HTTPTimeout; value != "" {
+		parsed, err := parseInterval(value)
+		if err != nil || parsed <= 0 {
+			errorf("Warning: ignoring invalid http_timeout %q in config file\n", value)
+		} else {
+			timeout = parsed
+		}
+	}
+	return &http.Client{Timeout: timeout}
+}
+
+// doRequest is the single path every API call goes through.
+// It sends req with client, logs it, and records the exchange when tracing is enabled.
+// With --dry-run, requests that change server state are printed instead of sent.
+func doRequest(client *http.Client, req *http.Request) (*http.Response, error) {
+	applyExtraHeaders(req)
+
+	if dryRun && isMutating(req) {
+		return dryRunResponse(req)
+	}
+
+	requestsInFlight.Add(1)
+	defer requestsInFlight.Add(-1)
+
+	start := time.Now()
+	var resp *http.Response
+	var err error
+	if traceFile == "" {
+		resp, err = client.Do(req)
+	} else {
+		resp, err = doTracedRequest(client, req)
+	}
+	logRequest(req, resp, err, time.Since(start))
+	return resp, err
+}
+
+// doRequestWithRetry sends req like doRequest, retrying transient failures with
+// exponential backoff. Network errors, 5xx and 429 responses are retried for idempotent
+// methods, waiting as long as a 429's Retry-After asks up to maxRetryAfter; other
+// methods, such as POST, are only retried when the request never reached the server
+// (see requestNeverSent), so a slow send cannot be delivered twice.
+func doRequestWithRetry(client *http.Client, req *http.Request) (*http.Response, error) {
+	idempotent := req.Method == http.MethodGet || req.Method == http.MethodHead ||
+		req.Method == http.MethodPut || req.Method == http.MethodDelete
+
+	for attempt := 0; ; attempt++ {
+		resp, err := doRequest(client, req)
+
+		delay := retryDelays[min(attempt, len(retryDelays)-1)]
+		rateLimited := err == nil && resp.StatusCode == http.StatusTooManyRequests
+		if rateLimited {
+			if wait := retryAfter(resp.Header); wait > 0 {
+				delay = wait
+			}
+		}
+
+		// A cancelled request is not retried
+		retryable := (err != nil && req.Context().Err() == nil && (idempotent || requestNeverSent(err))) ||
+			(err == nil && idempotent && (resp.StatusCode >= 500 || rateLimited))
+		if !retryable || attempt == len(retryDelays) || delay > maxRetryAfter {
+			return resp, err
+		}
+
+		// The body of the previous attempt has been consumed; get a fresh copy
+		if req.Body != nil {
+			if req.GetBody == nil {
+				return resp, err
+			}
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			req.Body = body
+		}
+
+		logger.Debug("retrying request", "method", req.Method, "attempt", attempt+1, "delay", delay)
+		switch {
+		case err != nil:
+			infof("Request failed (%v), retrying in %s...\n", err, delay)
+		case rateLimited:
+			resp.Body.Close()
+			infof("Rate limited, retrying in %s...\n", delay.Round(time.Second))
+		default:
+			resp.Body.Close()
+			infof("Server error (status %d), retrying in %s...\n", resp.StatusCode, delay)
+		}
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// requestNeverSent reports whether err shows the request never left the client:
+// the host name could not be resolved or no connection could be made. Other
+// failures, such as a timeout while waiting for the response, may happen after
+// the server has already acted on the request.
+func requestNeverSent(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// isSuccess reports whether an API status code counts as success: any 2xx code
+// from --min-status-code (200 by default) up
+func isSuccess(statusCode int) bool {
+	return statusCode >= max(minStatusCode, 200) && statusCode < 300
+}
+
+// applyExtraHeaders adds the configured extra_headers and --header values to req.
+// Command-line headers take precedence over config ones, and neither replaces
+// an Authorization header the request already carries.
+func applyExtraHeaders(req *http.Request) {
+	hasAuth := req.Header.Get("Authorization") != ""
+	for _, headers := range []map[string]string{loadConfig().ExtraHeaders, extraHeaders} {
+		for key, value := range headers {
+			if hasAuth && strings.EqualFold(key, "Authorization") {
+				continue
+			}
+			req.Header.Set(key, value)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestBackend points the client at a mock server running handler and gives
+// the test its own config directory
+func newTestBackend(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("CHAT_APP_BASE_URL", server.URL)
+}
+
+// writeJSON replies to a mock request with status and v encoded as JSON
+func writeJSON(t *testing.T, w http.ResponseWriter, status int, v any) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Errorf("encoding response: %v", err)
+	}
+}
+
+func TestIsSuccess(t *testing.T) {
+	tests := []struct {
+		status  int
+		minCode int
+		want    bool
+	}{
+		{http.StatusOK, 200, true},
+		{http.StatusCreated, 200, true},
+		{http.StatusAccepted, 200, true},
+		{http.StatusNoContent, 200, true},
+		{http.StatusMultipleChoices, 200, false},
+		{http.StatusBadRequest, 200, false},
+		{http.StatusOK, 201, false},
+		{http.StatusCreated, 201, true},
+		{http.StatusAccepted, 201, true},
+		{http.StatusOK, 202, false},
+		{http.StatusCreated, 202, false},
+		{http.StatusAccepted, 202, true},
+		{http.StatusMultipleChoices, 202, false},
+	}
+
+	t.Cleanup(func() { minStatusCode = 200 })
+	for _, tt := range tests {
+		minStatusCode = tt.minCode
+		if got := isSuccess(tt.status); got != tt.want {
+			t.Errorf("isSuccess(%d) with --min-status-code %d = %v, want %v", tt.status, tt.minCode, got, tt.want)
+		}
+	}
+}
+
+func TestSetMinStatusCode(t *testing.T) {
+	t.Cleanup(func() { minStatusCode = 200 })
+	for _, value := range []string{"200", "202", "299"} {
+		if err := setMinStatusCode(value); err != nil {
+			t.Errorf("setMinStatusCode(%q): %v", value, err)
+		}
+	}
+	for _, value := range []string{"199", "300", "404", "abc", ""} {
+		if err := setMinStatusCode(value); err == nil {
+			t.Errorf("setMinStatusCode(%q) accepted an invalid code", value)
+		}
+	}
+}
+
+func TestAcceptedResponsesSucceed(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusCreated, http.StatusAccepted} {
+		newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		})
+		if err := respondToFriendRequest(&TokenData{Token: "tok"}, "alice", "accept"); err != nil {
+			t.Errorf("status %d: %v", status, err)
+		}
+	}
+}
+
+func TestHTTPTimeout(t *testing.T) {
+	loadConfig()
+	saved := appConfig.HTTPTimeout
+	appConfig.HTTPTimeout = "50ms"
+	t.Cleanup(func() { appConfig.HTTPTimeout = saved })
+
+	// The handler never answers until the test is over
+	unblock := make(chan struct{})
+	newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	})
+	t.Cleanup(func() { close(unblock) })
+
+	start := time.Now()
+	err := respondToFriendRequest(&TokenData{Token: "tok"}, "alice", "accept")
+	if err == nil || !strings.Contains(err.Error(), "Timeout") {
+		t.Fatalf("respondToFriendRequest error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("request took %s with a 50ms timeout", elapsed)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// stdinReader is shared by every prompt so input buffered by one read is not lost to the next
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// readLine reads a whole line from stdin and trims surrounding whitespace.
+// Unlike fmt.Scanln it keeps spaces inside the line and never leaves part of
+// the line behind for the next prompt.
+func readLine() (string, error) {
+	line, err := stdinReader.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimSpace(line), err
+}
+
+// stdinIsTerminal reports whether stdin is an interactive terminal. When it is
+// not, credentials are read as plain lines so scripts can pipe them in.
+func stdinIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// readPassword reads a password from stdin, hiding the input on a terminal.
+// When stdin is not a terminal the password is read as the next line.
+func readPassword() (string, error) {
+	if !stdinIsTerminal() {
+		password, err := readLine()
+		if err == io.EOF {
+			return "", fmt.Errorf("no password given on stdin")
+		}
+		return password, err
+	}
+
+	bytePassword, err := term.ReadPassword(int(os.Stdin.Fd()))
+	promptf("\n") // Print newline after hidden input
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(bytePassword)), nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"os"
+
+	"github.com/zalando/go-keyring"
+)
+
+// keyringService is the service name tokens are stored under in the system keyring
+const keyringService = "chat_app"
+
+// useKeyring reports whether tokens are kept in the system keyring
+// (token_storage "keyring" in config.json) instead of the token file
+func useKeyring() bool {
+	return loadConfig().TokenStorage == "keyring"
+}
+
+// readKeyringToken returns the active profile's token from the system keyring.
+// ok is false when the keyring holds no token or cannot be used, so the caller
+// falls back to the token file.
+func readKeyringToken() (data []byte, ok bool) {
+	secret, err := keyring.Get(keyringService, activeProfile())
+	if err != nil {
+		if !errors.Is(err, keyring.ErrNotFound) {
+			debugf("Keyring unavailable, using the token file: %v\n", err)
+		}
+		return nil, false
+	}
+	return []byte(secret), true
+}
+
+// writeKeyringToken stores the active profile's token in the system keyring and
+// removes the plaintext token file left from before. It reports whether the
+// keyring could be used; when it cannot the caller writes the token file instead.
+func writeKeyringToken(data []byte, tokenFile string) bool {
+	if err := keyring.Set(keyringService, activeProfile(), string(data)); err != nil {
+		errorf("Warning: could not save the token in the system keyring, using %s: %v\n", tokenFile, err)
+		return false
+	}
+
+	if err := os.Remove(tokenFile); err != nil && !os.IsNotExist(err) {
+		errorf("Warning: could not remove the old token file %s: %v\n", tokenFile, err)
+	}
+	debugf("Token saved in the system keyring\n")
+	return true
+}
+
+// deleteKeyringToken removes the active profile's token from the system keyring,
+// if it is there
+func deleteKeyringToken() {
+	if err := keyring.Delete(keyringService, activeProfile()); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		debugf("Could not remove the token from the keyring: %v\n", err)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// maxLogSize is the size at which app.log is rotated
+	maxLogSize = 1 << 20
+	// maxLogBackups is how many rotated logs (app.log.1, app.log.2, ...) are kept
+	maxLogBackups = 3
+)
+
+// logger records API requests to ~/.config/chat_app/logs/app.log when --log-level
+// is given. It never records request headers or bodies, so tokens and passwords
+// stay out of the log.
+var logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// setupLogging enables the log file at level ("debug", "info", "warn" or "error")
+func setupLogging(level string) error {
+	var logLevel slog.Level
+	if err := logLevel.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid --log-level %q: use debug, info, warn or error", level)
+	}
+
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+	writer, err := openRotatingLog(filepath.Join(dir, "logs", "app.log"))
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %v", err)
+	}
+
+	logger = slog.New(slog.NewTextHandler(writer, &slog.HandlerOptions{Level: logLevel}))
+	return nil
+}
+
+// logRequest records one API request and its outcome
+func logRequest(req *http.Request, resp *http.Response, err error, duration time.Duration) {
+	// The URL never carries credentials; the query is dropped all the same
+	url := *req.URL
+	url.RawQuery = ""
+
+	if err != nil {
+		logger.Warn("request failed", "method", req.Method, "url", url.String(), "duration", duration, "error", err)
+		return
+	}
+	if resp.StatusCode >= 400 {
+		logger.Warn("request", "method", req.Method, "url", url.String(), "status", resp.StatusCode, "duration", duration)
+		return
+	}
+	logger.Info("request", "method", req.Method, "url", url.String(), "status", resp.StatusCode, "duration", duration)
+}
+
+// rotatingLog is an append-only log file that is renamed to app.log.1 once it
+// reaches maxLogSize, shifting older backups along and dropping the oldest
+type rotatingLog struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	size int64
+}
+
+// openRotatingLog opens the log at path for appending, creating its directory
+func openRotatingLog(path string) (*rotatingLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	l := &rotatingLog{path: path}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// open opens the current log file and records its size
+func (l *rotatingLog) open() error {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	l.file, l.size = file, info.Size()
+	return nil
+}
+
+// Write appends p to the log, rotating first when it would grow past maxLogSize
+func (l *rotatingLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.size > 0 && l.size+int64(len(p)) > maxLogSize {
+		if err := l.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := l.file.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+// rotate shifts app.log to app.log.1, app.log.1 to app.log.2 and so on, and
+// starts a new app.log
+func (l *rotatingLog) rotate() error {
+	l.file.Close()
+	for i := maxLogBackups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", l.path, i), fmt.Sprintf("%s.%d", l.path, i+1))
+	}
+	if err := os.Rename(l.path, l.path+".1"); err != nil {
+		return err
+	}
+	return l.open()
+}
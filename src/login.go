@@ -14,16 +14,60 @@ func login_() error {
 	// Get username and password from command line arguments or user input
 	var username, password string
 	
-	if len(os.Args) == 3 {
-		username = os.Args[1]
-		password = os.Args[2]
+	if len(os.Args) == 4 {
+		username = os.Args[2]
+		password = os.Args[3]
 	} else {
-		fmt.Print("Enter username: ")
-		fmt.Scanln(&username)
-		fmt.Print("Enter password: ")
-		fmt.Scanln(&password)
+		username, password = promptLoginCredentials()
 	}
 
+	// A faked login response has no token, so say what would happen instead
+	if dryRun {
+		fmt.Fprintf(os.Stderr, "[dry-run] would log in as %s at %s\n", username, apiURL("/login"))
+		return nil
+	}
+
+	token, err := loginWithCredentials(username, password)
+	if err != nil {
+		return err
+	}
+
+	if !loadConfig().HideRequestBadge {
+		showPendingRequestsBadge(token)
+	}
+	return nil
+}
+
+// showPendingRequestsBadge prints how many friend requests are waiting, if any.
+// It is best-effort: a failure to fetch the requests is ignored.
+func showPendingRequestsBadge(token *TokenData) {
+	requests, err := fetchIncomingFriendRequests(token, apiURL("/auth/get_incoming_friend_requests"))
+	if err != nil {
+		debugf("Could not check friend requests: %v\n", err)
+		return
+	}
+
+	switch {
+	case requests.TotalIncoming == 1:
+		fmt.Println("You have 1 pending friend request (run `requests`)")
+	case requests.TotalIncoming > 1:
+		fmt.Printf("You have %d pending friend requests (run `requests`)\n", requests.TotalIncoming)
+	}
+}
+
+// promptLoginCredentials asks the user for a username and password. When stdin
+// is not a terminal they are read from its first two lines instead.
+func promptLoginCredentials() (string, string) {
+	var username, password string
+	promptf("Enter username: ")
+	username, _ = readLine()
+	promptf("Enter password: ")
+	password, _ = readPassword()
+	return username, password
+}
+
+// loginWithCredentials authenticates against the API and saves the returned token
+func loginWithCredentials(username, password string) (*TokenData, error) {
 	// Create login request
 	loginReq := LoginRequest{
 		Username: username,
@@ -33,56 +77,65 @@ func login_() error {
 	// Convert to JSON
 	jsonData, err := json.Marshal(loginReq)
 	if err != nil {
-		return fmt.Errorf("error marshaling JSON: %v", err)
+		return nil, fmt.Errorf("error marshaling JSON: %v", err)
 	}
 
-	fmt.Printf("Sending JSON: %s\n", string(jsonData))
+	// Never echo the plaintext password
+	debugf("Sending JSON: %s\n", redactBody(jsonData))
 
 	// Create HTTP client with more detailed request
-	client := &http.Client{}
+	client := newHTTPClient()
 	
 	// Create request
-	req, err := http.NewRequest("POST", "https://wasalbackend-production.up.railway.app/login", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(appContext(), "POST", apiURL("/login"), bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Errorf("error creating request: %v", err)
+		return nil, fmt.Errorf("error creating request: %v", err)
 	}
 
 	// Set headers exactly as in curl
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "Go-http-client/1.1")
 
-	fmt.Printf("Making request to: %s\n", req.URL.String())
-	fmt.Printf("Headers: %+v\n", req.Header)
+	debugf("Making request to: %s\n", req.URL.String())
+	debugf("Headers: %+v\n", req.Header)
 
 	// Make the request
-	resp, err := client.Do(req)
+	resp, err := doRequest(client, req)
 	if err != nil {
-		return fmt.Errorf("error making request: %v", err)
+		return nil, fmt.Errorf("error making request: %v", err)
 	}
 	defer resp.Body.Close()
 
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("error reading response: %v", err)
+		return nil, fmt.Errorf("error reading response: %v", err)
 	}
 
-	fmt.Printf("Response status: %d\n", resp.StatusCode)
-	fmt.Printf("Response headers: %+v\n", resp.Header)
-	fmt.Printf("Response body: %s\n", string(body))
+	debugf("Response status: %d\n", resp.StatusCode)
+	debugf("Response headers: %+v\n", resp.Header)
+	debugf("Response body: %s\n", redactBody(body))
 
 	// Check if request was successful
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("login failed with status %d: %s", resp.StatusCode, string(body))
+	if !isSuccess(resp.StatusCode) {
+		return nil, newAPIError(resp, body)
 	}
 
 	// Parse response
 	var loginResp LoginResponse
 	if err := json.Unmarshal(body, &loginResp); err != nil {
-		return fmt.Errorf("error parsing response: %v", err)
+		return nil, parseError(err, body)
+	}
+
+	// Some backends report errors in the body of a 200 response
+	if loginResp.Token == "" {
+		if loginResp.Message != "" {
+			return nil, fmt.Errorf("login failed: %s", loginResp.Message)
+		}
+		return nil, fmt.Errorf("login failed: server response did not include a token")
 	}
 
-	fmt.Printf("Login successful: %s\n", loginResp.Message)
+	infoln("Login successful")
 
 	// Prepare token data to save
 	tokenData := TokenData{
@@ -90,45 +143,47 @@ func login_() error {
 		ExpiresIn: loginResp.ExpiresIn,
 		UserID:    loginResp.UserID,
 		Username:  loginResp.Username,
+		IssuedFor: apiBaseURL(),
 	}
 
 	// Save token to file
 	if err := saveToken(tokenData); err != nil {
-		return fmt.Errorf("error saving token: %v", err)
+		return nil, fmt.Errorf("error saving token: %v", err)
 	}
 
-	fmt.Println("Token saved successfully to ~/.config/chat_app/token.json")
-	return nil
+	return &tokenData, nil
 }
 
+// saveToken stores the token in the system keyring when it is enabled and available,
+// otherwise in the active profile's token file
 func saveToken(tokenData TokenData) error {
-	// Get home directory
-	homeDir, err := os.UserHomeDir()
+	// Each profile keeps its own token file
+	tokenFile, err := tokenFilePath()
 	if err != nil {
-		return fmt.Errorf("error getting home directory: %v", err)
+		return err
 	}
-
-	// Create the config directory path
-	configDir := filepath.Join(homeDir, ".config", "chat_app")
 	
 	// Create directories if they don't exist
-	if err := os.MkdirAll(configDir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(tokenFile), 0700); err != nil {
 		return fmt.Errorf("error creating config directory: %v", err)
 	}
 
-	// Create the token file path
-	tokenFile := filepath.Join(configDir, "token.json")
-
 	// Convert token data to JSON
 	jsonData, err := json.MarshalIndent(tokenData, "", "  ")
 	if err != nil {
 		return fmt.Errorf("error marshaling token data: %v", err)
 	}
 
+	if useKeyring() && writeKeyringToken(jsonData, tokenFile) {
+		return nil
+	}
+
 	// Write to file
 	if err := os.WriteFile(tokenFile, jsonData, 0600); err != nil {
 		return fmt.Errorf("error writing token file: %v", err)
 	}
 
+	debugf("Token saved to %s\n", tokenFile)
+
 	return nil
 }
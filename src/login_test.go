@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLoginWithoutToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    map[string]string
+		wantErr string
+	}{
+		{"error message in body", map[string]string{"message": "Invalid credentials"}, "login failed: Invalid credentials"},
+		{"empty token", map[string]string{"token": ""}, "did not include a token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(t, w, http.StatusOK, tt.body)
+			})
+
+			_, err := loginWithCredentials("me", "secret")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("loginWithCredentials error = %v, want one containing %q", err, tt.wantErr)
+			}
+
+			tokenFile, err := tokenFilePath()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(tokenFile); !os.IsNotExist(err) {
+				t.Errorf("a token file was saved after a failed login")
+			}
+		})
+	}
+}
+
+func TestLoginSavesToken(t *testing.T) {
+	newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, http.StatusOK, LoginResponse{Token: "abc", UserID: "1", Username: "me", ExpiresIn: "24h"})
+	})
+
+	token, err := loginWithCredentials("me", "secret")
+	if err != nil {
+		t.Fatalf("loginWithCredentials: %v", err)
+	}
+	if token.Token != "abc" || token.UserID != "1" {
+		t.Errorf("token = %+v", token)
+	}
+}
+
+func TestLoginAndSignupRateLimited(t *testing.T) {
+	newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	})
+
+	_, loginErr := loginWithCredentials("me", "secret")
+	signupErr := registerUser("me", "secret")
+	for name, err := range map[string]error{"login": loginErr, "signup": signupErr} {
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+			t.Errorf("%s error = %v, want an API error with status 429", name, err)
+			continue
+		}
+		if err.Error() != "rate limited, try again in 30s" {
+			t.Errorf("%s error = %q", name, err)
+		}
+	}
+}
@@ -6,78 +6,292 @@ import (
 )
 
 func main() {
-	// Check if command is provided
+	// Apply global flags and strip them from the arguments
+	args, err := parseGlobalFlags(os.Args[1:])
+	if err != nil {
+		errorf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	os.Args = append(os.Args[:1], args...)
+
+	if _, err := profileConfig(); err != nil {
+		errorf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// CTRL+C aborts a slow request instead of killing the process mid-request,
+	// and no signal leaves the terminal in raw mode
+	cancelOnInterrupt()
+	restoreOnTerminate()
+
+	// Without a command, offer the interactive menu (or the usage outside a terminal)
 	if len(os.Args) < 2 {
-		fmt.Println("Usage: go run main.go <command>")
-		fmt.Println("Available commands:")
-		fmt.Println("  signup                   - User registration")
+		showMainMenu()
 		return
 	}
 
-	command := os.Args[1]
+	runCommand(os.Args[1])
+}
 
-	// Execute based on command
+// printUsage lists the commands and global flags
+func printUsage() {
+	fmt.Println("Usage: go run main.go <command>")
+	fmt.Println("Available commands:")
+	fmt.Println("  signup [--no-login]      - User registration, logging in afterwards")
+	fmt.Println("  search --id <user_id>    - Send a friend request by user ID")
+	fmt.Println("  search <username> [--json] - Look up a user once without sending a request")
+	fmt.Println("  send [message]           - Send a message (compose multiple lines if omitted)")
+	fmt.Println("  send --to-id <id> | --to-username <name> [message] - Send without picking a friend")
+	fmt.Println("  send --attach <file>     - Upload a file and send it with the message")
+	fmt.Println("  broadcast [message]      - Send the same message to several friends")
+	fmt.Println("  receive --no-refresh-after-send - Don't re-fetch the conversation after sending")
+	fmt.Println("  receive --poll           - Auto-refresh the conversation (--interval 5s)")
+	fmt.Println("  receive --page-size N    - Messages per page (default 20, 0 for all)")
+	fmt.Println("  receive --limit N --before <id> - Only fetch the N messages before a message ID")
+	fmt.Println("  send/receive --confirm   - Confirm the recipient before sending")
+	fmt.Println("  send/receive --offline   - Pick the friend from the cached friends list")
+	fmt.Println("  receive --pager          - Show long conversations through $PAGER")
+	fmt.Println("  receive --once --to-username <user> - Print the conversation once and exit, without marking it read")
+	fmt.Println("  receive --since <date|24h> - Only show messages after a date or within a duration")
+	fmt.Println("  receive --watch          - Print new messages as they arrive, like tail -f (--interval 5s)")
+	fmt.Println("  requests --watch         - Watch incoming friend requests (--refresh-interval 10s, --verbose)")
+	fmt.Println("  requests --accept-all-from <user> - Accept every pending request from a user")
+	fmt.Println("  search-messages <term>   - Search messages across all friends (--limit N, --json)")
+	fmt.Println("  export --all [--force]   - Export every conversation (resumes unless --force)")
+	fmt.Println("  contacts import <file>   - Send friend requests to usernames from a CSV/JSON file")
+	fmt.Println("  invite --file <file>     - Send friend requests to usernames listed one per line")
+	fmt.Println("  whoami                   - Show the account you are logged in as")
+	fmt.Println("  passwd                   - Change your password")
+	fmt.Println("  delete-account --yes-really - Permanently delete your account")
+	fmt.Println("  ping                     - Check that the backend is reachable (no login needed)")
+	fmt.Println("  remove                   - Remove a friend")
+	fmt.Println("  alias add <name> <user>  - Give a friend a nickname (alias rm <name>, alias list)")
+	fmt.Println("  friends                  - List your friends")
+	fmt.Println("  friends/send/receive --sort name|date|recent - Order the friends list")
+	fmt.Println("  refresh-friends          - Re-fetch friends, keeping ones added locally")
+	fmt.Println("  friend-info [username]   - Show friendship details and conversation stats")
+	fmt.Println("  resend                   - Retry the last message that failed to send")
+	fmt.Println("  flush                    - Send messages queued while offline")
+	fmt.Println("  status                   - Show unread message counts per friend")
+	fmt.Println("  help                     - Show this list (run without a command for a menu)")
+	fmt.Println("  version, --version       - Show the version of this build")
+	fmt.Println("  history                  - Save a conversation to a file (--format text|json|csv, --out <path>)")
+	fmt.Println("Global flags:")
+	fmt.Println("  --trace-file <path>      - Record HTTP requests to a HAR-style JSON file")
+	fmt.Println("  --passphrase <phrase>    - Encrypt local state files (or set CHAT_APP_PASSPHRASE)")
+	fmt.Println("  --profile <name>         - Use a backend profile from config.json (default production)")
+	fmt.Println("  --min-status-code <code> - Lowest 2xx status treated as success (default 200)")
+	fmt.Println("  --dry-run                - Print requests that would change anything instead of sending them")
+	fmt.Println("  --header \"Key: Value\"    - Add a header to every request (repeatable)")
+	fmt.Println("  --no-color               - Disable colored output (or set NO_COLOR)")
+	fmt.Println("  --debug                  - Show request and response details")
+	fmt.Println("  --log-level <level>      - Log requests to ~/.config/chat_app/logs/app.log (debug, info, warn, error)")
+	fmt.Println("  --json                   - Print JSON for friends, requests and send")
+	fmt.Println("  --quiet                  - Print nothing on success (send prints the message ID)")
+	fmt.Println("Environment:")
+	fmt.Println("  CHAT_APP_BASE_URL        - Backend URL (overrides base_url in config.json)")
+	fmt.Println("  CHAT_APP_PROFILE         - Backend profile to use (like --profile)")
+	fmt.Println("  LANG / LC_ALL            - Non-UTF-8 locales show ASCII message markers (set \"theme\" in config.json to override)")
+}
+
+// runCommand executes command with the arguments in os.Args[2:]
+func runCommand(command string) {
 	switch command {
+	case "help", "--help":
+		printUsage()
+
 	case "signup":
 		// Execute signup process
 		err := ExecuteSignup()
 		if err != nil {
-			fmt.Printf("Signup failed: %v\n", err)
+			errorf("Signup failed: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println("Signup completed successfully!")
+		if !dryRun {
+			infoln("Signup completed successfully!")
+		}
 	
 	case "search":
                                 
 		err := friend()
 		if err != nil {
-			fmt.Printf("Search failed: %v\n", err)
+			errorf("Search failed: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println("Search completed successfully!")
+		infoln("Search completed successfully!")
 
 	case "login":
                                 
 		err := login_()
 		if err != nil {
-			fmt.Printf("Login failed: %v\n", err)
+			errorf("Login failed: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println("Login completed successfully!")
+		if !dryRun {
+			infoln("Login completed successfully!")
+		}
 
 	case "send":
                                 
 		err := send_message()
 		if err != nil {
-			fmt.Printf("Message failed: %v\n", err)
+			errorf("Message failed: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println("Message sent successfully!")
+		infoln("Message sent successfully!")
 
 	case "receive":
                                 
 		err := receive_message()
 		if err != nil {
-			fmt.Printf("Message failed: %v\n", err)
+			errorf("Message failed: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println("Message received successfully!")
+		infoln("Message received successfully!")
 
        case "requests":
                                 
 		err := manageFriendRequests()
 		if err != nil {
-			fmt.Printf("Requests failed: %v\n", err)
+			errorf("Requests failed: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println("Requests received successfully!")
+		infoln("Requests received successfully!")
 
 
 
+	case "search-messages":
+		err := searchMessages()
+		if err != nil {
+			errorf("Search failed: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "export":
+		err := exportConversations()
+		if err != nil {
+			errorf("Export failed: %v\n", err)
+			os.Exit(1)
+		}
+		infoln("Export completed successfully!")
 
+	case "contacts":
+		err := manageContacts()
+		if err != nil {
+			errorf("Contacts failed: %v\n", err)
+			os.Exit(1)
+		}
+		infoln("Contacts imported successfully!")
+
+	case "invite":
+		err := invite()
+		if err != nil {
+			errorf("Invite failed: %v\n", err)
+			os.Exit(1)
+		}
+		infoln("Invitations sent!")
+
+	case "whoami":
+		err := whoami()
+		if err != nil {
+			errorf("Whoami failed: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "passwd":
+		err := changePassword()
+		if err != nil {
+			errorf("Password change failed: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "delete-account":
+		err := deleteAccount()
+		if err != nil {
+			errorf("Account deletion failed: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "remove":
+		err := removeFriendCommand()
+		if err != nil {
+			errorf("Remove failed: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "friends":
+		err := listFriends()
+		if err != nil {
+			errorf("Listing friends failed: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "friend-info":
+		err := friendInfo()
+		if err != nil {
+			errorf("Friend info failed: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "history":
+		err := saveHistory()
+		if err != nil {
+			errorf("History failed: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "status":
+		err := showStatus()
+		if err != nil {
+			errorf("Status failed: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "resend":
+		err := resendMessage()
+		if err != nil {
+			errorf("Resend failed: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "broadcast":
+		err := broadcast()
+		if err != nil {
+			errorf("Broadcast failed: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "flush":
+		err := flushCommand()
+		if err != nil {
+			errorf("Flush failed: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "alias":
+		err := manageAliases()
+		if err != nil {
+			errorf("Alias failed: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "ping":
+		if err := ping(); err != nil {
+			os.Exit(1)
+		}
+
+	case "version", "--version":
+		printVersion()
+
+	case "refresh-friends":
+		err := refreshFriends()
+		if err != nil {
+			errorf("Refresh friends failed: %v\n", err)
+			os.Exit(1)
+		}
 
 	default:
-		fmt.Printf("Error: Unknown command '%s'\n", command)
-		fmt.Println("Use 'go run main.go' to see available commands")
+		errorf("Error: Unknown command '%s'\n", command)
+		errorf("Use 'go run main.go' to see available commands\n")
 	}
 }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// MarkReadRequest represents the payload for marking messages as read
+type MarkReadRequest struct {
+	FriendUserID string `json:"friend_user_id"`
+	MessageIDs   []int  `json:"message_ids"`
+}
+
+// markedRead remembers message IDs already reported as read during this session
+var markedRead = make(map[int]bool)
+
+// markDisplayedRead tells the server that the unread messages from friend in
+// conversation have been viewed. It is best-effort: failures are only reported.
+func markDisplayedRead(token *TokenData, friend *Friend, conversation *ConversationResponse) {
+	var messageIDs []int
+	for _, msg := range filterConversation(token, friend, conversation) {
+		if msg.Sender != token.UserID && !msg.IsRead && !markedRead[msg.MessageID] {
+			messageIDs = append(messageIDs, msg.MessageID)
+		}
+	}
+	if len(messageIDs) == 0 {
+		return
+	}
+
+	if err := markConversationRead(token, friend.GetUserID(), messageIDs); err != nil {
+		errorf("Warning: could not mark messages as read: %v\n", err)
+		return
+	}
+
+	for _, id := range messageIDs {
+		markedRead[id] = true
+	}
+	for i := range conversation.Conversation {
+		if markedRead[conversation.Conversation[i].MessageID] {
+			conversation.Conversation[i].IsRead = true
+		}
+	}
+}
+
+// markConversationRead asks the API to mark the given messages from friendUserID as read
+func markConversationRead(token *TokenData, friendUserID string, messageIDs []int) error {
+	jsonData, err := json.Marshal(MarkReadRequest{FriendUserID: friendUserID, MessageIDs: messageIDs})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(appContext(), "POST", apiURL("/auth/mark_read"), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := newHTTPClient()
+	resp, err := doRequest(client, req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %v", err)
+	}
+
+	if !isSuccess(resp.StatusCode) {
+		return newAPIError(resp, body)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"golang.org/x/term"
+)
+
+// menuItem is one entry of the interactive main menu
+type menuItem struct {
+	command     string
+	description string
+	shownWhen   menuState
+}
+
+// menuState is the login state in which a menu entry is shown
+type menuState int
+
+const (
+	always menuState = iota
+	whenLoggedIn
+	whenLoggedOut
+)
+
+// mainMenu lists the commands offered by the interactive menu
+var mainMenu = []menuItem{
+	{"signup", "Create an account", whenLoggedOut},
+	{"login", "Log in", always},
+	{"send", "Send a message", whenLoggedIn},
+	{"receive", "Open a conversation", whenLoggedIn},
+	{"broadcast", "Send a message to several friends", whenLoggedIn},
+	{"status", "Show unread messages", whenLoggedIn},
+	{"friends", "List your friends", whenLoggedIn},
+	{"search", "Search users and send friend requests", whenLoggedIn},
+	{"requests", "Manage friend requests", whenLoggedIn},
+	{"search-messages", "Search your messages", whenLoggedIn},
+	{"history", "Save a conversation to a file", whenLoggedIn},
+	{"remove", "Remove a friend", whenLoggedIn},
+	{"whoami", "Show the account you are logged in as", whenLoggedIn},
+	{"ping", "Check the connection to the server", always},
+	{"help", "Show all commands and flags", always},
+}
+
+// showMainMenu lets the user pick a command by number when none was given.
+// The entries depend on whether a token is saved. Outside a terminal the usage is printed instead.
+func showMainMenu() {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		printUsage()
+		return
+	}
+
+	path, err := tokenFilePath()
+	_, tokenErr := readTokenData(path)
+	state := whenLoggedOut
+	if err == nil && tokenErr == nil {
+		state = whenLoggedIn
+	}
+
+	var items []menuItem
+	for _, item := range mainMenu {
+		if item.shownWhen == always || item.shownWhen == state {
+			items = append(items, item)
+		}
+	}
+
+	fmt.Println("=== Chat App ===")
+	if state == whenLoggedOut {
+		fmt.Println("You are not logged in.")
+	}
+	for i, item := range items {
+		fmt.Printf("%2d. %-16s %s\n", i+1, item.command, item.description)
+	}
+	fmt.Printf("\nChoose an option (1-%d, or press Enter to quit): ", len(items))
+
+	input, _ := readLine()
+	if input == "" {
+		return
+	}
+	choice, err := strconv.Atoi(input)
+	if err != nil || choice < 1 || choice > len(items) {
+		errorf("Invalid choice: please select a number between 1 and %d\n", len(items))
+		os.Exit(1)
+	}
+
+	command := items[choice-1].command
+	os.Args = append(os.Args[:1], command)
+	runCommand(command)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// selectableMessages is how many of the most recent messages are offered when
+// picking a message to act on
+const selectableMessages = 10
+
+// editWindow is how long after sending a message it can still be edited
+const editWindow = 15 * time.Minute
+
+// reactionPalette is the emoji offered when reacting to a message
+var reactionPalette = []string{"👍", "❤️", "😂", "😮", "😢", "🙏"}
+
+// pickOwnMessage is pickMessage limited to messages you sent; choosing a
+// received message is an error
+func pickOwnMessage(token *TokenData, friend *Friend, action string) (*Message, error) {
+	msg, err := pickMessage(token, friend, action)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Sender != token.UserID {
+		return nil, fmt.Errorf("you can only %s messages you sent", action)
+	}
+	return msg, nil
+}
+
+// pickMessage lists the most recent messages with friend and asks for the number
+// of one to act on, described by action such as "delete"
+func pickMessage(token *TokenData, friend *Friend, action string) (*Message, error) {
+	conversation, err := getConversation(token, friend)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching conversation: %v", err)
+	}
+
+	messages := filterConversation(token, friend, conversation)
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("there are no messages to %s", action)
+	}
+	messages = messages[max(len(messages)-selectableMessages, 0):]
+
+	fmt.Println("\n--- Recent Messages ---")
+	for i, msg := range messages {
+		sender := colorize(ansiGreen, friend.GetUsername())
+		if msg.Sender == token.UserID {
+			sender = colorize(ansiCyan, "You")
+		}
+		fmt.Printf("%d. %s: %s (ID: %d)\n", i+1, sender, snippet(messageText(msg), "", 50), msg.MessageID)
+	}
+
+	fmt.Printf("\nEnter the number of the message to %s: ", action)
+	input, _ := readLine()
+	choice, err := strconv.Atoi(input)
+	if err != nil || choice < 1 || choice > len(messages) {
+		return nil, fmt.Errorf("invalid choice: please select a number between 1 and %d", len(messages))
+	}
+	return &messages[choice-1], nil
+}
+
+// handleDeleteMessage lets you pick one of your sent messages, deletes it after
+// confirmation and refreshes the conversation
+func handleDeleteMessage(token *TokenData, friend *Friend) error {
+	msg, err := pickOwnMessage(token, friend, "delete")
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Delete %q? [y/N]: ", snippet(messageText(*msg), "", 50))
+	answer, _ := readLine()
+	if answer != "y" && answer != "Y" && answer != "yes" {
+		fmt.Println("Message not deleted.")
+		return nil
+	}
+
+	if err := NewAPIClient(token.Token).DeleteMessage(msg.MessageID); err != nil {
+		return fmt.Errorf("failed to delete message: %v", err)
+	}
+	fmt.Println("🗑️  Message deleted.")
+
+	conversationPage = 0
+	return fetchConversation(token, friend)
+}
+
+// handleReactToMessage lets you pick a message and react to it with an emoji
+// from reactionPalette, then refreshes the conversation
+func handleReactToMessage(token *TokenData, friend *Friend) error {
+	msg, err := pickMessage(token, friend, "react to")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	for i, emoji := range reactionPalette {
+		fmt.Printf("%d. %s  ", i+1, emoji)
+	}
+	fmt.Print("\nEnter the number of the reaction: ")
+	input, _ := readLine()
+	choice, err := strconv.Atoi(input)
+	if err != nil || choice < 1 || choice > len(reactionPalette) {
+		return fmt.Errorf("invalid choice: please select a number between 1 and %d", len(reactionPalette))
+	}
+
+	emoji := reactionPalette[choice-1]
+	if err := NewAPIClient(token.Token).ReactToMessage(msg.MessageID, emoji); err != nil {
+		return fmt.Errorf("failed to react to message: %v", err)
+	}
+	fmt.Printf("%s Reaction added.\n", emoji)
+
+	conversationPage = 0
+	return fetchConversation(token, friend)
+}
+
+// handleEditMessage lets you pick one of your messages sent within editWindow,
+// replaces its text and refreshes the conversation
+func handleEditMessage(token *TokenData, friend *Friend) error {
+	msg, err := pickOwnMessage(token, friend, "edit")
+	if err != nil {
+		return err
+	}
+
+	sentAt, err := parseMessageTime(msg.Timestamp)
+	if err != nil {
+		return fmt.Errorf("cannot tell when message %d was sent: %v", msg.MessageID, err)
+	}
+	if time.Since(sentAt) > editWindow {
+		return fmt.Errorf("messages can only be edited within %v of sending", editWindow)
+	}
+
+	fmt.Printf("Editing: %s\n", colorize(ansiDim, snippet(messageText(*msg), "", 50)))
+	message, err := composeMessage(stdinReader)
+	if err != nil {
+		return fmt.Errorf("error reading message input: %v", err)
+	}
+	if message == "" {
+		fmt.Println("Message cannot be empty. Edit cancelled.")
+		return nil
+	}
+
+	if err := NewAPIClient(token.Token).EditMessage(msg.MessageID, message); err != nil {
+		return fmt.Errorf("failed to edit message: %v", err)
+	}
+	fmt.Println("✏️  Message edited.")
+
+	conversationPage = 0
+	return fetchConversation(token, friend)
+}
@@ -31,9 +31,32 @@ type APIResponse struct {
 
 // Friend represents a friend entry
 type Friend struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	AddedAt  string `json:"added_at"`
+	// API response fields
+	FriendID       string `json:"friend_id"`
+	FriendUsername string `json:"friend_username"`
+	FriendshipDate string `json:"friendship_date"`
+	FriendshipID   int    `json:"friendship_id"`
+
+	// Legacy/alternative fields for backward compatibility
+	UserID   string `json:"user_id,omitempty"`
+	Username string `json:"username,omitempty"`
+	AddedAt  string `json:"added_at,omitempty"`
+}
+
+// GetUserID returns the appropriate user ID field
+func (f *Friend) GetUserID() string {
+	if f.FriendID != "" {
+		return f.FriendID
+	}
+	return f.UserID
+}
+
+// GetUsername returns the appropriate username field
+func (f *Friend) GetUsername() string {
+	if f.FriendUsername != "" {
+		return f.FriendUsername
+	}
+	return f.Username
 }
 
 // FriendsData represents the structure of friends.json
@@ -168,16 +191,20 @@ func addFriend(friendsPath, userID, username string) error {
 
 	// Check if friend already exists
 	for _, friend := range friendsData.Friends {
-		if friend.UserID == userID {
+		if friend.GetUserID() == userID {
 			return fmt.Errorf("user %s is already in friends list", username)
 		}
 	}
 
-	// Add new friend
+	// Add new friend, filling both the API and legacy fields so either reader works
+	addedAt := time.Now().Format(time.RFC3339)
 	newFriend := Friend{
-		UserID:   userID,
-		Username: username,
-		AddedAt:  time.Now().Format(time.RFC3339),
+		FriendID:       userID,
+		FriendUsername: username,
+		FriendshipDate: addedAt,
+		UserID:         userID,
+		Username:       username,
+		AddedAt:        addedAt,
 	}
 	friendsData.Friends = append(friendsData.Friends, newFriend)
 
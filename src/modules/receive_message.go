@@ -22,9 +22,32 @@ type TokenData struct {
 
 // Friend represents a friend in the friends list
 type Friend struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	AddedAt  string `json:"added_at"`
+	// API response fields
+	FriendID       string `json:"friend_id"`
+	FriendUsername string `json:"friend_username"`
+	FriendshipDate string `json:"friendship_date"`
+	FriendshipID   int    `json:"friendship_id"`
+
+	// Legacy/alternative fields for backward compatibility
+	UserID   string `json:"user_id,omitempty"`
+	Username string `json:"username,omitempty"`
+	AddedAt  string `json:"added_at,omitempty"`
+}
+
+// GetUserID returns the appropriate user ID field
+func (f *Friend) GetUserID() string {
+	if f.FriendID != "" {
+		return f.FriendID
+	}
+	return f.UserID
+}
+
+// GetUsername returns the appropriate username field
+func (f *Friend) GetUsername() string {
+	if f.FriendUsername != "" {
+		return f.FriendUsername
+	}
+	return f.Username
 }
 
 // FriendsData represents the structure of the friends file
@@ -148,7 +171,7 @@ func readFriendsFromConfig() (*FriendsData, error) {
 func selectFriend(friends *FriendsData) (*Friend, error) {
 	fmt.Println("\n--- Your Friends ---")
 	for i, friend := range friends.Friends {
-		fmt.Printf("%d. %s (ID: %s)\n", i+1, friend.Username, friend.UserID)
+		fmt.Printf("%d. %s (ID: %s)\n", i+1, friend.GetUsername(), friend.GetUserID())
 	}
 	
 	fmt.Print("\nEnter the number of the friend whose conversation you want to view: ")
@@ -168,7 +191,7 @@ func selectFriend(friends *FriendsData) (*Friend, error) {
 	
 	// Return selected friend (subtract 1 for 0-based indexing)
 	selectedFriend := &friends.Friends[choiceNum-1]
-	fmt.Printf("Selected: %s\n", selectedFriend.Username)
+	fmt.Printf("Selected: %s\n", selectedFriend.GetUsername())
 	
 	return selectedFriend, nil
 }
@@ -176,7 +199,7 @@ func selectFriend(friends *FriendsData) (*Friend, error) {
 // fetchConversation fetches and displays the conversation with the selected friend
 func fetchConversation(token *TokenData, friend *Friend) error {
 	// Build API URL
-	url := fmt.Sprintf("http://localhost:2000/auth/conversation/%s", friend.UserID)
+	url := fmt.Sprintf("http://localhost:2000/auth/conversation/%s", friend.GetUserID())
 	
 	// Create HTTP request
 	req, err := http.NewRequest("GET", url, nil)
@@ -221,7 +244,7 @@ func fetchConversation(token *TokenData, friend *Friend) error {
 
 // displayConversation displays the filtered conversation between you and the selected friend
 func displayConversation(token *TokenData, friend *Friend, conversation *ConversationResponse) {
-	fmt.Printf("\n=== Conversation with %s ===\n", friend.Username)
+	fmt.Printf("\n=== Conversation with %s ===\n", friend.GetUsername())
 	fmt.Printf("Total messages in conversation: %d\n", conversation.TotalMessages)
 	fmt.Printf("Participants: %v\n", conversation.Participants)
 	fmt.Println(strings.Repeat("=", 50))
@@ -232,18 +255,18 @@ func displayConversation(token *TokenData, friend *Friend, conversation *Convers
 		// Only include messages where either:
 		// - You sent to this friend (sender = your ID, recipient = friend ID)
 		// - This friend sent to you (sender = friend ID, recipient = your ID)
-		if (msg.Sender == token.UserID && msg.Recipient == friend.UserID) ||
-		   (msg.Sender == friend.UserID && msg.Recipient == token.UserID) {
+		if (msg.Sender == token.UserID && msg.Recipient == friend.GetUserID()) ||
+		   (msg.Sender == friend.GetUserID() && msg.Recipient == token.UserID) {
 			filteredMessages = append(filteredMessages, msg)
 		}
 	}
 
 	if len(filteredMessages) == 0 {
-		fmt.Printf("No messages found between you and %s.\n", friend.Username)
+		fmt.Printf("No messages found between you and %s.\n", friend.GetUsername())
 		return
 	}
 
-	fmt.Printf("\nMessages between you and %s (%d messages):\n\n", friend.Username, len(filteredMessages))
+	fmt.Printf("\nMessages between you and %s (%d messages):\n\n", friend.GetUsername(), len(filteredMessages))
 
 	// Display filtered messages
 	for _, msg := range filteredMessages {
@@ -267,7 +290,7 @@ func displayConversation(token *TokenData, friend *Friend, conversation *Convers
 			}
 		} else {
 			// Message received from friend
-			fmt.Printf("📥 [%s] %s: %s\n", timeStr, friend.Username, msg.Message)
+			fmt.Printf("📥 [%s] %s: %s\n", timeStr, friend.GetUsername(), msg.Message)
 			if !msg.IsRead {
 				fmt.Printf("   Status: Unread\n")
 			} else {
@@ -279,5 +302,5 @@ func displayConversation(token *TokenData, friend *Friend, conversation *Convers
 		fmt.Println(strings.Repeat("-", 40))
 	}
 
-	fmt.Printf("\nEnd of conversation with %s\n", friend.Username)
+	fmt.Printf("\nEnd of conversation with %s\n", friend.GetUsername())
 }
@@ -21,9 +21,32 @@ type TokenData struct {
 
 // Friend represents a friend in the friends list
 type Friend struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	AddedAt  string `json:"added_at"`
+	// API response fields
+	FriendID       string `json:"friend_id"`
+	FriendUsername string `json:"friend_username"`
+	FriendshipDate string `json:"friendship_date"`
+	FriendshipID   int    `json:"friendship_id"`
+
+	// Legacy/alternative fields for backward compatibility
+	UserID   string `json:"user_id,omitempty"`
+	Username string `json:"username,omitempty"`
+	AddedAt  string `json:"added_at,omitempty"`
+}
+
+// GetUserID returns the appropriate user ID field
+func (f *Friend) GetUserID() string {
+	if f.FriendID != "" {
+		return f.FriendID
+	}
+	return f.UserID
+}
+
+// GetUsername returns the appropriate username field
+func (f *Friend) GetUsername() string {
+	if f.FriendUsername != "" {
+		return f.FriendUsername
+	}
+	return f.Username
 }
 
 // FriendsData represents the structure of the friends file
@@ -83,13 +106,13 @@ func main() {
 	}
 
 	// Send message to selected friend
-	err = sendMessage(token.Token, message, selectedFriend.UserID)
+	err = sendMessage(token.Token, message, selectedFriend.GetUserID())
 	if err != nil {
 		fmt.Printf("Error sending message: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Message sent successfully to %s!\n", selectedFriend.Username)
+	fmt.Printf("Message sent successfully to %s!\n", selectedFriend.GetUsername())
 }
 
 // readTokenFromConfig reads the token from ~/.config/chat_app/token.json
@@ -154,7 +177,7 @@ func readFriendsFromConfig() (*FriendsData, error) {
 func selectFriend(friends *FriendsData) (*Friend, error) {
 	fmt.Println("\n--- Your Friends ---")
 	for i, friend := range friends.Friends {
-		fmt.Printf("%d. %s (ID: %s)\n", i+1, friend.Username, friend.UserID)
+		fmt.Printf("%d. %s (ID: %s)\n", i+1, friend.GetUsername(), friend.GetUserID())
 	}
 	
 	fmt.Print("\nEnter the number of the friend you want to send the message to: ")
@@ -174,7 +197,7 @@ func selectFriend(friends *FriendsData) (*Friend, error) {
 	
 	// Return selected friend (subtract 1 for 0-based indexing)
 	selectedFriend := &friends.Friends[choiceNum-1]
-	fmt.Printf("Selected: %s\n", selectedFriend.Username)
+	fmt.Printf("Selected: %s\n", selectedFriend.GetUsername())
 	
 	return selectedFriend, nil
 }
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+)
+
+// outboxFile holds messages that could not be sent while offline, oldest first
+const outboxFile = "outbox.json"
+
+// OutboxEntry is a message waiting to be sent once the backend is reachable again
+type OutboxEntry struct {
+	MessageRequest
+	QueuedAt string `json:"queued_at"`
+}
+
+// isOfflineError reports whether err means the backend could not be reached at
+// all, as opposed to the backend rejecting the request. Timeouts and other
+// failures after the request was sent are not counted, since the server may
+// already have the message and queueing it would deliver it twice.
+func isOfflineError(err error) bool {
+	return requestNeverSent(err) && !errors.Is(err, context.Canceled)
+}
+
+// readOutbox returns the queued messages; a missing outbox is empty
+func readOutbox() ([]OutboxEntry, error) {
+	data, err := readStateFile(outboxFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", outboxFile, err)
+	}
+
+	var entries []OutboxEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", outboxFile, err)
+	}
+	return entries, nil
+}
+
+// writeOutbox saves the queued messages, removing the outbox when none are left
+func writeOutbox(entries []OutboxEntry) error {
+	if len(entries) == 0 {
+		return removeStateFile(outboxFile)
+	}
+
+	jsonData, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %v", outboxFile, err)
+	}
+	return writeStateFile(outboxFile, jsonData)
+}
+
+// queueMessage adds a message to the end of the outbox
+func queueMessage(request MessageRequest) error {
+	entries, err := readOutbox()
+	if err != nil {
+		return err
+	}
+
+	entries = append(entries, OutboxEntry{
+		MessageRequest: request,
+		QueuedAt:       time.Now().Format(time.RFC3339),
+	})
+	return writeOutbox(entries)
+}
+
+// flushOutbox sends the queued messages in order. Delivered messages are removed;
+// messages the backend rejects with a 4xx status are dropped and the reason logged.
+// Flushing stops at the first message that cannot be delivered yet, keeping it and
+// the rest queued. It returns how many were sent and how many are left.
+// With --dry-run nothing is sent and the outbox is left as it is.
+func flushOutbox(token string) (sent, left int, err error) {
+	entries, err := readOutbox()
+	if err != nil || len(entries) == 0 {
+		return 0, 0, err
+	}
+	if dryRun {
+		return 0, len(entries), nil
+	}
+
+	client := NewAPIClient(token)
+	remaining := entries
+	for len(remaining) > 0 {
+		entry := remaining[0]
+		_, sendErr := client.SendMessageRequest(entry.MessageRequest)
+
+		var apiErr *APIError
+		if sendErr != nil && !(errors.As(sendErr, &apiErr) && apiErr.StatusCode >= 400 && apiErr.StatusCode < 500) {
+			err = fmt.Errorf("could not send queued message to %s: %v", entry.RecipientUserID, sendErr)
+			break
+		}
+
+		if sendErr != nil {
+			errorf("Dropped queued message to %s from %s: %v\n", entry.RecipientUserID, entry.QueuedAt, sendErr)
+			logger.Warn("dropped queued message", "recipient", entry.RecipientUserID, "queued_at", entry.QueuedAt, "error", sendErr)
+		} else {
+			sent++
+		}
+		remaining = remaining[1:]
+	}
+
+	if writeErr := writeOutbox(remaining); writeErr != nil && err == nil {
+		err = writeErr
+	}
+	return sent, len(remaining), err
+}
+
+// autoFlushOutbox sends any queued messages before send and receive, warning
+// instead of failing when they cannot be delivered yet. It does nothing with --dry-run.
+func autoFlushOutbox(token string) {
+	if dryRun {
+		return
+	}
+	entries, err := readOutbox()
+	if err != nil || len(entries) == 0 {
+		return
+	}
+
+	infof("Sending %d queued message(s)...\n", len(entries))
+	sent, left, err := flushOutbox(token)
+	if sent > 0 {
+		infof("Sent %d queued message(s).\n", sent)
+	}
+	if err != nil {
+		errorf("Warning: %v (%d still queued)\n", err, left)
+	}
+}
+
+// flushCommand implements the flush command: it sends the queued messages
+func flushCommand() error {
+	token, err := requireToken()
+	if err != nil {
+		return err
+	}
+
+	entries, err := readOutbox()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("No queued messages.")
+		return nil
+	}
+
+	sent, left, err := flushOutbox(token.Token)
+	fmt.Printf("Sent %d queued message(s), %d still queued.\n", sent, left)
+	return err
+}
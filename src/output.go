@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// jsonOutput is set by --json: commands that support it print structured JSON to
+// stdout and the decorated human output is suppressed
+var jsonOutput bool
+
+// quiet is set by --quiet: decorative and success output is suppressed so a
+// successful command prints nothing (send prints just the new message ID) and
+// scripts rely on the exit code
+var quiet bool
+
+// infof prints human-oriented output, which is suppressed in JSON and quiet mode
+func infof(format string, a ...any) {
+	if !jsonOutput && !quiet {
+		fmt.Printf(format, a...)
+	}
+}
+
+// infoln is the Println form of infof
+func infoln(a ...any) {
+	if !jsonOutput && !quiet {
+		fmt.Println(a...)
+	}
+}
+
+// promptf prints an interactive prompt. In JSON and quiet mode prompts go to
+// stderr so they stay visible without mixing into the output on stdout.
+func promptf(format string, a ...any) {
+	if jsonOutput || quiet {
+		fmt.Fprintf(os.Stderr, format, a...)
+		return
+	}
+	fmt.Printf(format, a...)
+}
+
+// debugf prints verbose diagnostics to stderr when --debug is set
+func debugf(format string, a ...any) {
+	if debug {
+		fmt.Fprintf(os.Stderr, format, a...)
+	}
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v any) error {
+	jsonData, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON output: %v", err)
+	}
+	fmt.Println(string(jsonData))
+	return nil
+}
+
+// errorf prints an error or warning to stderr, keeping stdout for the command's
+// actual output so piped and --json output stays clean
+func errorf(format string, a ...any) {
+	fmt.Fprintf(os.Stderr, format, a...)
+}
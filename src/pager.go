@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// usePager sends long conversation output through $PAGER (receive --pager)
+var usePager bool
+
+// showPaged prints text, piping it through the user's pager when paging is enabled,
+// stdout is a terminal and text is taller than the terminal. It falls back to plain
+// printing when no pager can be run.
+func showPaged(text string) {
+	if !usePager || jsonOutput || !needsPager(text) {
+		fmt.Print(text)
+		return
+	}
+
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less"
+	}
+	fields := strings.Fields(pager)
+	path, err := exec.LookPath(fields[0])
+	if err != nil {
+		fmt.Print(text)
+		return
+	}
+
+	cmd := exec.Command(path, fields[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// Let less show colors and quit when the text fits after all
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+
+	if err := cmd.Start(); err != nil {
+		fmt.Print(text)
+		return
+	}
+	cmd.Wait()
+}
+
+// needsPager reports whether stdout is a terminal and text is taller than it
+func needsPager(text string) bool {
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		return false
+	}
+	_, height, err := term.GetSize(fd)
+	if err != nil {
+		return false
+	}
+	return strings.Count(text, "\n") >= height
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+)
+
+// changePassword asks for the current password and a new one, changes it on the
+// server and logs in again with the new password, since the server may revoke
+// the old token
+func changePassword() error {
+	token, err := requireToken()
+	if err != nil {
+		return err
+	}
+
+	promptf("Current password: ")
+	currentPassword, err := readPassword()
+	if err != nil {
+		return fmt.Errorf("failed to read current password: %v", err)
+	}
+
+	promptf("New password: ")
+	newPassword, err := readPassword()
+	if err != nil {
+		return fmt.Errorf("failed to read new password: %v", err)
+	}
+	if err := validatePassword(newPassword); err != nil {
+		return err
+	}
+	if newPassword == currentPassword {
+		return fmt.Errorf("the new password must be different from the current one")
+	}
+
+	// As with signup, piped input gives the new password only once
+	if stdinIsTerminal() {
+		promptf("Confirm new password: ")
+		confirmPassword, err := readPassword()
+		if err != nil {
+			return fmt.Errorf("failed to read password confirmation: %v", err)
+		}
+		if newPassword != confirmPassword {
+			return fmt.Errorf("passwords do not match")
+		}
+	}
+
+	if err := NewAPIClient(token.Token).ChangePassword(currentPassword, newPassword); err != nil {
+		return err
+	}
+	infoln("✅ Password changed.")
+
+	// Refresh the token with the new password
+	if _, err := loginWithCredentials(token.Username, newPassword); err != nil {
+		return fmt.Errorf("password changed but logging in again failed (run `login`): %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// PingResult is the outcome of checking whether the backend is reachable
+type PingResult struct {
+	URL        string  `json:"url"`
+	Reachable  bool    `json:"reachable"`
+	StatusCode int     `json:"status_code,omitempty"`
+	LatencyMS  float64 `json:"latency_ms"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// ping implements the ping command: it sends a HEAD request to the backend and
+// reports whether it answered and how long it took. It needs no token, so it can
+// be used to diagnose connectivity before logging in. Any HTTP response counts
+// as reachable.
+func ping() error {
+	result := PingResult{URL: apiBaseURL()}
+
+	req, err := http.NewRequestWithContext(appContext(), http.MethodHead, result.URL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+
+	start := time.Now()
+	resp, err := doRequest(newHTTPClient(), req)
+	result.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		resp.Body.Close()
+		result.Reachable = true
+		result.StatusCode = resp.StatusCode
+	}
+
+	if jsonOutput {
+		if err := printJSON(result); err != nil {
+			return err
+		}
+	} else if result.Reachable {
+		fmt.Printf("✅ %s is reachable (status %d, %.0f ms)\n", result.URL, result.StatusCode, result.LatencyMS)
+	} else {
+		fmt.Printf("❌ %s is unreachable after %.0f ms: %s\n", result.URL, result.LatencyMS, result.Error)
+	}
+
+	if !result.Reachable {
+		return fmt.Errorf("backend unreachable")
+	}
+	return nil
+}
@@ -1,19 +1,19 @@
 package main
 
 import (
-	"bufio"
 	"bytes"
+	"cmp"
+	"errors"
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
-	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
-	"syscall"
+	"sync"
+	"sync/atomic"
 	"time"
-	"unsafe"
 )
 
 // Message represents a single message in the conversation
@@ -25,6 +25,10 @@ type Message struct {
 	Recipient   string `json:"recipient"`
 	Sender      string `json:"sender"`
 	Timestamp   string `json:"timestamp"`
+	Edited      bool   `json:"edited,omitempty"`
+	Attachment  string `json:"attachment,omitempty"`
+	// Reactions counts the reactions to the message by emoji
+	Reactions map[string]int `json:"reactions,omitempty"`
 }
 
 // ConversationResponse represents the API response for conversation
@@ -34,207 +38,353 @@ type ConversationResponse struct {
 	TotalMessages int       `json:"total_messages"`
 }
 
+// defaultPollInterval is how often the conversation is re-fetched with --poll
+const defaultPollInterval = 5 * time.Second
+
+var (
+	// refreshAfterSend controls whether the conversation is re-fetched after sending from the receive view
+	refreshAfterSend = true
+	// pollInterval is how often the receive view checks for new messages; 0 disables polling
+	pollInterval time.Duration
+	// displayedTotal is the TotalMessages of the conversation last rendered
+	displayedTotal atomic.Int64
+	// messagesPerPage is how many messages are shown at once; 0 shows all
+	messagesPerPage = 20
+	// conversationPage is the page being shown, counting back from the most recent (0)
+	conversationPage int
+	// conversationSearch limits the conversation view to messages containing it; empty shows all
+	conversationSearch string
+	// conversationQuery limits how much of the conversation is fetched (--limit, --before)
+	conversationQuery ConversationQuery
+	// conversationSince hides messages sent before it (--since); zero shows all
+	conversationSince time.Time
+)
+
 func receive_message() error {
+	args := os.Args[2:]
+	refreshAfterSend = !hasFlag(args, "--no-refresh-after-send")
+	confirmSend = hasFlag(args, "--confirm") || loadConfig().ConfirmSend
+	usePager = hasFlag(args, "--pager")
+
+	// --poll enables auto-refresh at the default interval; --interval sets a custom one
+	if hasFlag(args, "--poll") {
+		pollInterval = defaultPollInterval
+	}
+	if value, ok := flagValue(args, "--interval"); ok {
+		interval, err := parseInterval(value)
+		if err != nil {
+			return err
+		}
+		pollInterval = max(interval, time.Second)
+	}
+	if value, ok := flagValue(args, "--page-size"); ok {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 0 {
+			return fmt.Errorf("invalid --page-size %q: must be 0 (all) or a positive number", value)
+		}
+		messagesPerPage = size
+	}
+	if value, ok := flagValue(args, "--limit"); ok {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return fmt.Errorf("invalid --limit %q: must be a positive number", value)
+		}
+		conversationQuery.Limit = limit
+	}
+	if value, ok := flagValue(args, "--before"); ok {
+		before, err := strconv.Atoi(value)
+		if err != nil || before <= 0 {
+			return fmt.Errorf("invalid --before %q: must be a message ID", value)
+		}
+		conversationQuery.Before = before
+	}
+	if value, ok := flagValue(args, "--since"); ok {
+		since, err := parseSince(value)
+		if err != nil {
+			return err
+		}
+		conversationSince = since
+	}
+
+	// --once (or --exit-on-read) prints the conversation and exits, for scripts and pipes
+	once := hasFlag(args, "--once") || hasFlag(args, "--exit-on-read")
+
 	// Read token from config file
-	token, err := readTokenForReceiveMessage()
+	token, err := requireToken()
 	if err != nil {
-		fmt.Printf("Error reading token: %v\n", err)
-		os.Exit(1)
+		return err
 	}
 
-	// Fetch friends from API instead of local file for consistency
-	friends, err := fetchFriendsFromAPI(token.Token)
+	// Deliver messages queued while offline before showing the conversation.
+	// A one-off read leaves the outbox alone, so it never sends anything.
+	if !once {
+		autoFlushOutbox(token.Token)
+	}
+
+	// Fetch friends from API, or from the cached friends.json with --offline
+	friends, err := loadFriends(token.Token, hasFlag(args, "--offline"))
 	if err != nil {
-		fmt.Printf("Error fetching friends: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("error fetching friends: %v", err)
 	}
 
 	// Check if friends list is empty
 	if len(friends.Friends) == 0 {
-		fmt.Println("No friends found in your friends list.")
-		os.Exit(1)
+		return fmt.Errorf("no friends found in your friends list")
 	}
 
-	// Display friends and ask user to select
-	selectedFriend, err := selectFriendForReceiveMessage(friends)
+	if err := applyFriendSort(token, friends.Friends, args); err != nil {
+		return err
+	}
+
+	// Use the friend given with --to-username or --to-id, or ask the user to pick one.
+	// --once never prompts, so it needs one of the flags.
+	selectedFriend, err := recipientFromFlags(friends, args)
+	if err == nil && selectedFriend == nil {
+		if once {
+			return fmt.Errorf("--once needs --to-username or --to-id")
+		}
+		selectedFriend, err = selectFriendForReceiveMessage(friends)
+	}
+	if errors.Is(err, errSelectionCancelled) {
+		return nil
+	}
 	if err != nil {
-		fmt.Printf("Error selecting friend: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("error selecting friend: %v", err)
+	}
+
+	// In --watch mode tail new messages without the interactive view
+	if hasFlag(args, "--watch") {
+		return watchConversation(token, selectedFriend)
+	}
+
+	// In --once mode print the conversation and exit without the interactive loop,
+	// leaving messages unread and the last-seen marker where it was
+	if once {
+		conversation, err := getConversation(token, selectedFriend)
+		if err != nil {
+			return fmt.Errorf("error fetching conversation: %v", err)
+		}
+		displayConversation(token, selectedFriend, conversation)
+		return nil
 	}
 
 	// Fetch initial conversation with selected friend
 	err = fetchConversation(token, selectedFriend)
 	if err != nil {
-		fmt.Printf("Error fetching conversation: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("error fetching conversation: %v", err)
 	}
 
 	// Wait for CTRL+R input to refresh, CTRL+S to send message, or CTRL+C to exit
-	fmt.Println("\nPress CTRL+R to refresh conversation, CTRL+S to send message, or CTRL+C to exit...")
-	waitForCtrlRInReceiveMessage(token, selectedFriend)
+	fmt.Println(receiveKeyHelp)
+	err = waitForCtrlRInReceiveMessage(token, selectedFriend)
+	seenMessages.flush()
 	
-	return nil
+	return err
 }
 
-// waitForCtrlRInReceiveMessage waits for CTRL+R key combination to refresh conversation or CTRL+S to send message
-func waitForCtrlRInReceiveMessage(token *TokenData, friend *Friend) {
-	// Set terminal to raw mode to capture key combinations
-	oldState, err := makeRawForReceiveMessage(int(os.Stdin.Fd()))
+// receiveKeyHelp lists the key bindings available in the conversation view
+const receiveKeyHelp = "\nPress CTRL+R to refresh conversation, CTRL+S to send message, CTRL+Y to reply, CTRL+D to delete, CTRL+U to edit or CTRL+E to react to a message, CTRL+P for earlier messages, CTRL+F to search, CTRL+G to jump to a message ID, or CTRL+C to exit..."
+
+// waitForCtrlRInReceiveMessage waits for CTRL+R key combination to refresh conversation or CTRL+S to send message.
+// It returns nil when the user exits with CTRL+C. Errors are returned rather than
+// exiting here, so the deferred terminal restore always runs.
+func waitForCtrlRInReceiveMessage(token *TokenData, friend *Friend) error {
+	fd := int(os.Stdin.Fd())
+
+	// Set terminal to raw mode to capture key combinations; where that is not
+	// possible, such as some Windows consoles or piped input, fall back to lines
+	oldState, err := makeRaw(fd)
 	if err != nil {
-		fmt.Printf("Error setting terminal to raw mode: %v\n", err)
-		return
+		debugf("Raw mode unavailable: %v\n", err)
+		return waitForEnterInReceiveMessage(token, friend)
 	}
-	defer restoreForReceiveMessage(int(os.Stdin.Fd()), oldState)
+	// Deferred so the terminal is restored even if a handler panics
+	defer restore(fd, oldState)
 
-	buffer := make([]byte, 1)
-	for {
-		n, err := os.Stdin.Read(buffer)
-		if err != nil {
-			fmt.Printf("Error reading input: %v\n", err)
-			return
+	// screenMu serializes terminal mode switches and output between key handlers and the poller
+	var screenMu sync.Mutex
+
+	// inCookedMode runs action with the terminal restored, then sets it back to raw mode
+	var rawErr error
+	inCookedMode := func(action func()) bool {
+		screenMu.Lock()
+		defer screenMu.Unlock()
+
+		restore(fd, oldState)
+		action()
+		fmt.Println(receiveKeyHelp)
+
+		if _, err := makeRaw(fd); err != nil {
+			rawErr = fmt.Errorf("error setting terminal to raw mode: %v", err)
+			return false
 		}
-		
-		if n > 0 {
-			// Check for CTRL+R (ASCII 18)
-			if buffer[0] == 18 {
-				// Restore terminal before fetching conversation
-				restoreForReceiveMessage(int(os.Stdin.Fd()), oldState)
-				
+		return true
+	}
+
+	// Poll for new messages in the background while keys are read
+	if pollInterval > 0 {
+		stop := make(chan struct{})
+		defer close(stop)
+		go pollConversation(token, friend, stop, inCookedMode)
+	}
+
+	// Each handler returns false to leave the view; CTRL+C does so straight away
+	err = dispatchKeys(os.Stdin, map[byte]func() bool{
+		18: func() bool { // CTRL+R
+			return inCookedMode(func() {
+				conversationPage = 0
 				fmt.Println("\n🔄 Refreshing conversation...")
-				err = fetchConversation(token, friend)
-				if err != nil {
-					fmt.Printf("Error refreshing conversation: %v\n", err)
-				}
-				
-				fmt.Println("\nPress CTRL+R to refresh conversation, CTRL+S to send message, or CTRL+C to exit...")
-				
-				// Set terminal back to raw mode
-				oldState, err = makeRawForReceiveMessage(int(os.Stdin.Fd()))
-				if err != nil {
-					fmt.Printf("Error setting terminal to raw mode: %v\n", err)
-					return
+				if err := fetchConversation(token, friend); err != nil {
+					errorf("Error refreshing conversation: %v\n", err)
 				}
-			}
-			// Check for CTRL+S (ASCII 19)
-			if buffer[0] == 19 {
-				// Restore terminal before sending message
-				restoreForReceiveMessage(int(os.Stdin.Fd()), oldState)
-				
+			})
+		},
+		19: func() bool { // CTRL+S
+			return inCookedMode(func() {
 				fmt.Println("\n💬 Send Message Mode")
-				err = handleSendMessage(token, friend)
-				if err != nil {
-					fmt.Printf("Error sending message: %v\n", err)
+				if err := handleSendMessage(token, friend, ""); err != nil {
+					errorf("Error sending message: %v\n", err)
 				}
-				
-				fmt.Println("\nPress CTRL+R to refresh conversation, CTRL+S to send message, or CTRL+C to exit...")
-				
-				// Set terminal back to raw mode
-				oldState, err = makeRawForReceiveMessage(int(os.Stdin.Fd()))
-				if err != nil {
-					fmt.Printf("Error setting terminal to raw mode: %v\n", err)
-					return
+			})
+		},
+		25: func() bool { // CTRL+Y
+			return inCookedMode(func() {
+				fmt.Println("\n↩️  Reply Mode")
+				if err := handleReply(token, friend); err != nil {
+					errorf("Error sending reply: %v\n", err)
 				}
-			}
-			// Check for CTRL+C (ASCII 3)
-			if buffer[0] == 3 {
-				fmt.Println("\nExiting...")
-				os.Exit(0)
-			}
-		}
-	}
-}
-
-// Terminal manipulation functions for Unix-like systems (for receive_message)
-type termiosReceiveMessage struct {
-	Iflag  uint32
-	Oflag  uint32
-	Cflag  uint32
-	Lflag  uint32
-	Cc     [20]uint8
-	Ispeed uint32
-	Ospeed uint32
-}
-
-func makeRawForReceiveMessage(fd int) (*termiosReceiveMessage, error) {
-	var oldState termiosReceiveMessage
-	
-	// Get current terminal settings
-	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), 0x5401, uintptr(unsafe.Pointer(&oldState)))
-	if errno != 0 {
-		return nil, errno
+			})
+		},
+		4: func() bool { // CTRL+D
+			return inCookedMode(func() {
+				if err := handleDeleteMessage(token, friend); err != nil {
+					errorf("Error deleting message: %v\n", err)
+				}
+			})
+		},
+		21: func() bool { // CTRL+U
+			return inCookedMode(func() {
+				if err := handleEditMessage(token, friend); err != nil {
+					errorf("Error editing message: %v\n", err)
+				}
+			})
+		},
+		5: func() bool { // CTRL+E
+			return inCookedMode(func() {
+				if err := handleReactToMessage(token, friend); err != nil {
+					errorf("Error reacting to message: %v\n", err)
+				}
+			})
+		},
+		16: func() bool { // CTRL+P
+			return inCookedMode(func() {
+				conversationPage++
+				fmt.Println("\n⏪ Loading earlier messages...")
+				if err := fetchConversation(token, friend); err != nil {
+					errorf("Error loading earlier messages: %v\n", err)
+				}
+			})
+		},
+		6: func() bool { // CTRL+F
+			return inCookedMode(func() {
+				if err := searchConversation(token, friend); err != nil {
+					errorf("Error searching conversation: %v\n", err)
+				}
+			})
+		},
+		7: func() bool { // CTRL+G
+			return inCookedMode(func() {
+				if err := jumpToMessage(token, friend); err != nil {
+					errorf("Error jumping to message: %v\n", err)
+				}
+			})
+		},
+		3: func() bool { // CTRL+C
+			return false
+		},
+	})
+
+	// screenMu stays locked so the poller cannot redraw while exiting
+	screenMu.Lock()
+	if err != nil {
+		return err
 	}
-	
-	// Create new settings for raw mode
-	newState := oldState
-	newState.Lflag &^= syscall.ICANON | syscall.ECHO
-	newState.Cc[syscall.VMIN] = 1
-	newState.Cc[syscall.VTIME] = 0
-	
-	// Apply new settings
-	_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), 0x5402, uintptr(unsafe.Pointer(&newState)))
-	if errno != 0 {
-		return nil, errno
+	if rawErr != nil {
+		return rawErr
 	}
-	
-	return &oldState, nil
+	restore(fd, oldState)
+	fmt.Println("\nExiting...")
+	return nil
 }
 
-func restoreForReceiveMessage(fd int, oldState *termiosReceiveMessage) error {
-	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), 0x5402, uintptr(unsafe.Pointer(oldState)))
-	if errno != 0 {
-		return errno
+// waitForEnterInReceiveMessage is the line-based fallback for the conversation view
+// when the terminal cannot be put into raw mode: Enter refreshes, "s" sends a message
+// and "q" or end of input exits
+func waitForEnterInReceiveMessage(token *TokenData, friend *Friend) error {
+	for {
+		fmt.Print("\nPress Enter to refresh, type s and Enter to send a message, or q to quit: ")
+		input, err := readLine()
+		if err != nil && input == "" {
+			return nil
+		}
+
+		switch strings.ToLower(input) {
+		case "":
+			conversationPage = 0
+			fmt.Println("\n🔄 Refreshing conversation...")
+			if err := fetchConversation(token, friend); err != nil {
+				errorf("Error refreshing conversation: %v\n", err)
+			}
+		case "s":
+			fmt.Println("\n💬 Send Message Mode")
+			if err := handleSendMessage(token, friend, ""); err != nil {
+				errorf("Error sending message: %v\n", err)
+			}
+		case "q":
+			fmt.Println("Exiting...")
+			return nil
+		default:
+			fmt.Printf("Unknown choice %q\n", input)
+		}
 	}
-	return nil
 }
 
-// readTokenForReceiveMessage reads the token from ~/.config/chat_app/token.json
-func readTokenForReceiveMessage() (*TokenData, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %v", err)
-	}
+// pollConversation re-fetches the conversation, starting every pollInterval and backing
+// off while nothing changes, and re-renders it through inCookedMode when the message
+// count changes. It returns when stop is closed.
+func pollConversation(token *TokenData, friend *Friend, stop <-chan struct{}, inCookedMode func(func()) bool) {
+	backoff := newPollBackoff(pollInterval)
 
-	tokenPath := filepath.Join(homeDir, ".config", "chat_app", "token.json")
-	
-	file, err := os.Open(tokenPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open token file: %v", err)
-	}
-	defer file.Close()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(backoff.next()):
+		}
 
-	data, err := io.ReadAll(file)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read token file: %v", err)
-	}
+		// Transient failures are skipped; the next poll tries again
+		conversation, err := getConversation(token, friend)
+		if err != nil || int64(conversation.TotalMessages) == displayedTotal.Load() {
+			backoff.update(false)
+			continue
+		}
+		backoff.update(true)
 
-	var tokenData TokenData
-	err = json.Unmarshal(data, &tokenData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse token file: %v", err)
+		inCookedMode(func() {
+			conversationPage = 0
+			fmt.Println("\n🔔 New messages")
+			displayConversation(token, friend, conversation)
+			markDisplayedRead(token, friend, conversation)
+		})
 	}
-
-	return &tokenData, nil
 }
 
 // readFriendsForReceiveMessage reads the friends list from ~/.config/chat_app/friends.json
 // Kept for backward compatibility but now also supports API fetching
 func readFriendsForReceiveMessage() (*FriendsData, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %v", err)
-	}
-
-	friendsPath := filepath.Join(homeDir, ".config", "chat_app", "friends.json")
-	
-	file, err := os.Open(friendsPath)
+	data, err := readStateFile("friends.json")
 	if err != nil {
-		return nil, fmt.Errorf("failed to open friends file: %v", err)
-	}
-	defer file.Close()
-
-	data, err := io.ReadAll(file)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read friends file: %v", err)
+		return nil, fmt.Errorf("failed to read friends file: %w", err)
 	}
 
 	var friendsData FriendsData
@@ -242,10 +392,25 @@ func readFriendsForReceiveMessage() (*FriendsData, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse friends file: %v", err)
 	}
+	for i := range friendsData.Friends {
+		NormalizeFriend(&friendsData.Friends[i])
+	}
 
 	return &friendsData, nil
 }
 
+// saveFriendsFile writes the friends list to ~/.config/chat_app/friends.json
+func saveFriendsFile(friends *FriendsData) error {
+	jsonData, err := json.MarshalIndent(friends, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode friends file: %v", err)
+	}
+	if err := writeStateFile("friends.json", jsonData); err != nil {
+		return fmt.Errorf("failed to write friends file: %v", err)
+	}
+	return nil
+}
+
 // selectFriendForReceiveMessage displays the friends list and asks user to select one
 func selectFriendForReceiveMessage(friends *FriendsData) (*Friend, error) {
 	fmt.Println("\n--- Your Friends ---")
@@ -254,24 +419,13 @@ func selectFriendForReceiveMessage(friends *FriendsData) (*Friend, error) {
 		userID := friend.GetUserID()
 		fmt.Printf("%d. %s (ID: %s)\n", i+1, username, userID)
 	}
+	fmt.Println("(Type a number or the start of a username, or q to cancel)")
 	
-	fmt.Print("\nEnter the number of the friend whose conversation you want to view: ")
-	var choice string
-	fmt.Scanln(&choice)
-	
-	// Convert choice to integer
-	choiceNum, err := strconv.Atoi(choice)
+	// Resolve the number or username to a friend
+	selectedFriend, err := promptForFriend(friends, "Enter the number of the friend whose conversation you want to view: ")
 	if err != nil {
-		return nil, fmt.Errorf("invalid choice: please enter a number")
+		return nil, err
 	}
-	
-	// Validate choice
-	if choiceNum < 1 || choiceNum > len(friends.Friends) {
-		return nil, fmt.Errorf("invalid choice: please select a number between 1 and %d", len(friends.Friends))
-	}
-	
-	// Return selected friend (subtract 1 for 0-based indexing)
-	selectedFriend := &friends.Friends[choiceNum-1]
 	fmt.Printf("Selected: %s\n", selectedFriend.GetUsername())
 	
 	return selectedFriend, nil
@@ -279,210 +433,531 @@ func selectFriendForReceiveMessage(friends *FriendsData) (*Friend, error) {
 
 // fetchConversation fetches and displays the conversation with the selected friend
 func fetchConversation(token *TokenData, friend *Friend) error {
-	// Build API URL using the appropriate user ID
-	friendUserID := friend.GetUserID()
-	url := fmt.Sprintf("https://wasalbackend-production.up.railway.app/auth/conversation/%s", friendUserID)
-	
-	// Create HTTP request
-	req, err := http.NewRequest("GET", url, nil)
+	conversation, err := getConversation(token, friend)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
+		return err
 	}
 
-	// Set authorization header
-	req.Header.Set("Authorization", "Bearer "+token.Token)
+	// Display conversation
+	displayConversation(token, friend, conversation)
+	markDisplayedRead(token, friend, conversation)
 
-	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send request: %v", err)
+	return nil
+}
+
+// getConversation fetches the conversation with the selected friend from the API
+func getConversation(token *TokenData, friend *Friend) (*ConversationResponse, error) {
+	return NewAPIClient(token.Token).GetConversationPage(friend.GetUserID(), conversationQuery)
+}
+
+// displayConversation displays the filtered conversation between you and the selected friend,
+// through the pager when enabled
+func displayConversation(token *TokenData, friend *Friend, conversation *ConversationResponse) {
+	var buf bytes.Buffer
+	renderConversation(&buf, token, friend, conversation)
+	showPaged(buf.String())
+}
+
+// renderConversation writes the filtered conversation between you and the selected friend to w
+func renderConversation(w io.Writer, token *TokenData, friend *Friend, conversation *ConversationResponse) {
+	friendUsername := friendDisplayName(friend)
+	displayedTotal.Store(int64(conversation.TotalMessages))
+	
+	// Clear screen for refresh (optional - uncomment if you want to clear screen on refresh)
+	// fmt.Print("\033[2J\033[H")
+	
+	fmt.Fprintf(w, "\n=== Conversation with %s ===\n", friendUsername)
+	fmt.Fprintf(w, "Total messages in conversation: %d\n", conversation.TotalMessages)
+	fmt.Fprintf(w, "Participants: %v\n", conversation.Participants)
+	fmt.Fprintf(w, "Last updated: %s\n", time.Now().Format("Jan 2, 2006 at 3:04 PM"))
+	fmt.Fprintln(w, strings.Repeat("=", 50))
+
+	// Filter messages between you and the selected friend only
+	filteredMessages := filterConversation(token, friend, conversation)
+
+	if len(filteredMessages) == 0 {
+		fmt.Fprintf(w, "No messages found between you and %s.\n", friendUsername)
+		return
 	}
-	defer resp.Body.Close()
 
-	// Read response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response: %v", err)
+	fmt.Fprintf(w, "\nMessages between you and %s (%d messages):\n", friendUsername, len(filteredMessages))
+
+	// Count messages from the friend that arrived since the last session
+	lastSeenID := seenMessages.lastSeen(friend.GetUserID())
+	if lastSeenID > 0 {
+		newCount := 0
+		for _, msg := range filteredMessages {
+			if msg.MessageID > lastSeenID && msg.Sender != token.UserID {
+				newCount++
+			}
+		}
+		if newCount > 0 {
+			fmt.Fprintf(w, "%d new message(s) since you last checked\n", newCount)
+		}
 	}
 
-	// Check if request was successful
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	// Hide messages sent before --since
+	shownMessages := filteredMessages
+	if !conversationSince.IsZero() {
+		shownMessages = sinceMessages(filteredMessages, conversationSince)
+		fmt.Fprintf(w, "⏱  Showing messages since %s (%d earlier message(s) hidden)\n",
+			conversationSince.Format("Jan 2, 2006 at 3:04 PM"), len(filteredMessages)-len(shownMessages))
 	}
 
-	// Parse response
-	var conversation ConversationResponse
-	err = json.Unmarshal(body, &conversation)
-	if err != nil {
-		return fmt.Errorf("failed to parse response: %v", err)
+	// Narrow the view to messages matching the current search
+	if conversationSearch != "" {
+		matching := shownMessages
+		shownMessages = nil
+		for _, msg := range matching {
+			if containsFold(msg.Message, conversationSearch) {
+				shownMessages = append(shownMessages, msg)
+			}
+		}
+		fmt.Fprintf(w, "🔍 %d message(s) matching %q (press CTRL+F and Enter to show all)\n", len(shownMessages), conversationSearch)
 	}
 
-	// Display conversation
-	displayConversation(token, friend, &conversation)
-	
-	return nil
+	// Work out which page of messages to show, counting back from the most recent
+	start, end := pageBounds(w, len(shownMessages))
+	if len(shownMessages) > 0 {
+		fmt.Fprintf(w, "Showing messages %d–%d of %d\n", start+1, end, len(shownMessages))
+	}
+	if start > 0 {
+		fmt.Fprintln(w, "Press CTRL+P to load earlier messages")
+	}
+	fmt.Fprintln(w)
+
+	// Display filtered messages
+	for _, msg := range shownMessages[start:end] {
+		printMessage(w, token, friendUsername, msg)
+	}
+
+	fmt.Fprintf(w, "\nEnd of conversation with %s\n", friendUsername)
+	displayReceiptSummary(w, token, filteredMessages)
+
+	latestID := 0
+	for _, msg := range filteredMessages {
+		latestID = max(latestID, msg.MessageID)
+	}
+	seenMessages.markSeen(friend.GetUserID(), latestID)
 }
 
-// displayConversation displays the filtered conversation between you and the selected friend
-func displayConversation(token *TokenData, friend *Friend, conversation *ConversationResponse) {
-	friendUsername := friend.GetUsername()
+// displayReceiptSummary prints how many of your messages have been read or only
+// delivered, and how many of your friend's messages you have not read yet
+func displayReceiptSummary(w io.Writer, token *TokenData, messages []Message) {
+	var read, delivered, unread int
+	for _, msg := range messages {
+		switch {
+		case msg.Sender == token.UserID && msg.IsRead:
+			read++
+		case msg.Sender == token.UserID:
+			delivered++
+		case !msg.IsRead:
+			unread++
+		}
+	}
+	fmt.Fprintf(w, "Your messages: %d read, %d delivered | Unread from friend: %d\n", read, delivered, unread)
+}
+
+// pageBounds returns the slice bounds of the current page for total messages.
+// Page 0 is the most recent messagesPerPage messages; conversationPage is clamped
+// to the oldest page.
+func pageBounds(w io.Writer, total int) (start, end int) {
+	if messagesPerPage <= 0 {
+		return 0, total
+	}
+
+	lastPage := max((total-1)/messagesPerPage, 0)
+	if conversationPage > lastPage {
+		conversationPage = lastPage
+		fmt.Fprintln(w, "Already showing the earliest messages.")
+	}
+
+	end = total - conversationPage*messagesPerPage
+	start = max(end-messagesPerPage, 0)
+	return start, end
+}
+
+// filterConversation returns only the messages exchanged between you and the selected friend.
+// A message the API returned more than once is kept once, preferring the read copy.
+func filterConversation(token *TokenData, friend *Friend, conversation *ConversationResponse) []Message {
 	friendUserID := friend.GetUserID()
-	
-	// Clear screen for refresh (optional - uncomment if you want to clear screen on refresh)
-	// fmt.Print("\033[2J\033[H")
-	
-	fmt.Printf("\n=== Conversation with %s ===\n", friendUsername)
-	fmt.Printf("Total messages in conversation: %d\n", conversation.TotalMessages)
-	fmt.Printf("Participants: %v\n", conversation.Participants)
-	fmt.Printf("Last updated: %s\n", time.Now().Format("Jan 2, 2006 at 3:04 PM"))
-	fmt.Println(strings.Repeat("=", 50))
 
-	// Filter messages between you and the selected friend only
 	var filteredMessages []Message
+	indexByID := make(map[int]int)
 	for _, msg := range conversation.Conversation {
 		// Only include messages where either:
 		// - You sent to this friend (sender = your ID, recipient = friend ID)
 		// - This friend sent to you (sender = friend ID, recipient = your ID)
 		if (msg.Sender == token.UserID && msg.Recipient == friendUserID) ||
-		   (msg.Sender == friendUserID && msg.Recipient == token.UserID) {
+			(msg.Sender == friendUserID && msg.Recipient == token.UserID) {
+			if i, ok := indexByID[msg.MessageID]; ok {
+				filteredMessages[i] = moreCompleteMessage(filteredMessages[i], msg)
+				continue
+			}
+			indexByID[msg.MessageID] = len(filteredMessages)
 			filteredMessages = append(filteredMessages, msg)
 		}
 	}
 
-	if len(filteredMessages) == 0 {
-		fmt.Printf("No messages found between you and %s.\n", friendUsername)
-		return
-	}
+	sortMessages(filteredMessages)
+	return filteredMessages
+}
 
-	fmt.Printf("\nMessages between you and %s (%d messages):\n\n", friendUsername, len(filteredMessages))
+// messageTimeLayout is the format of message timestamps sent by the API
+const messageTimeLayout = "2006-01-02 15:04:05"
 
-	// Display filtered messages
-	for _, msg := range filteredMessages {
-		// Parse timestamp
-		timestamp, err := time.Parse("2006-01-02 15:04:05", msg.Timestamp)
-		var timeStr string
-		if err != nil {
-			timeStr = msg.Timestamp // Use original if parsing fails
-		} else {
-			timeStr = timestamp.Format("Jan 2, 2006 at 3:04 PM")
+// messageTimeLocation is the time zone of message timestamps, which carry no
+// offset of their own. The API sends them in UTC.
+var messageTimeLocation = time.UTC
+
+// parseMessageTime parses a message timestamp. Every comparison of message times
+// goes through here so they are all read in messageTimeLocation.
+func parseMessageTime(timestamp string) (time.Time, error) {
+	return time.ParseInLocation(messageTimeLayout, timestamp, messageTimeLocation)
+}
+
+// parseSince parses a --since value: a date ("2024-01-01"), a date and time
+// ("2024-01-01 15:04:05" or RFC 3339), or a Go duration such as "24h" counted back from now.
+// Dates without an offset are read in messageTimeLocation, like message timestamps.
+func parseSince(value string) (time.Time, error) {
+	if duration, err := time.ParseDuration(value); err == nil && duration > 0 {
+		return time.Now().Add(-duration), nil
+	}
+	for _, layout := range []string{"2006-01-02", messageTimeLayout, time.RFC3339} {
+		if since, err := time.ParseInLocation(layout, value, messageTimeLocation); err == nil {
+			return since, nil
 		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: use a date like 2024-01-01 or a duration like 24h", value)
+}
+
+// sinceMessages returns the messages sent at or after since. Messages whose
+// timestamp cannot be parsed are kept, since their age is unknown.
+func sinceMessages(messages []Message, since time.Time) []Message {
+	var recent []Message
+	for _, msg := range messages {
+		sentAt, err := parseMessageTime(msg.Timestamp)
+		if err != nil || !sentAt.Before(since) {
+			recent = append(recent, msg)
+		}
+	}
+	return recent
+}
 
-		// Determine message direction and display accordingly
-		if msg.Sender == token.UserID {
-			// Message sent by you
-			fmt.Printf("📤 [%s] You: %s\n", timeStr, msg.Message)
-			if !msg.IsRead {
-				fmt.Printf("   Status: Delivered\n")
-			} else {
-				fmt.Printf("   Status: Read\n")
+// sortMessages orders messages oldest first by timestamp, since the API does not
+// guarantee an order. Ties and unparseable timestamps are ordered by message ID.
+func sortMessages(messages []Message) {
+	slices.SortStableFunc(messages, func(a, b Message) int {
+		timeA, errA := parseMessageTime(a.Timestamp)
+		timeB, errB := parseMessageTime(b.Timestamp)
+		if errA == nil && errB == nil {
+			if c := timeA.Compare(timeB); c != 0 {
+				return c
 			}
+		}
+		return cmp.Compare(a.MessageID, b.MessageID)
+	})
+}
+
+// moreCompleteMessage picks which of two copies of the same message to keep:
+// a read copy wins over an unread one, then an edited one over the original
+func moreCompleteMessage(kept, duplicate Message) Message {
+	if duplicate.IsRead != kept.IsRead {
+		if duplicate.IsRead {
+			return duplicate
+		}
+		return kept
+	}
+	if duplicate.Edited && !kept.Edited {
+		return duplicate
+	}
+	return kept
+}
+
+// printMessage prints a single message with its status lines
+func printMessage(w io.Writer, token *TokenData, friendUsername string, msg Message) {
+	// Parse timestamp
+	timestamp, err := parseMessageTime(msg.Timestamp)
+	var timeStr string
+	if err != nil {
+		timeStr = msg.Timestamp // Use original if parsing fails
+	} else {
+		timeStr = humanizeTime(timestamp)
+	}
+
+	// Determine message direction and display accordingly
+	theme := currentTheme()
+	if msg.Sender == token.UserID {
+		// Message sent by you
+		prefix := fmt.Sprintf("%s[%s] %s: ", theme.sent, colorize(ansiDim, timeStr), colorize(ansiCyan, "You"))
+		fmt.Fprintln(w, highlightedMessage(prefix, messageText(msg), ansiCyan))
+		if !msg.IsRead {
+			fmt.Fprintf(w, "   Status: Delivered\n")
 		} else {
-			// Message received from friend
-			fmt.Printf("📥 [%s] %s: %s\n", timeStr, friendUsername, msg.Message)
-			if !msg.IsRead {
-				fmt.Printf("   Status: Unread\n")
-			} else {
-				fmt.Printf("   Status: Read\n")
-			}
+			fmt.Fprintf(w, "   Status: Read\n")
+		}
+	} else {
+		// Message received from friend
+		prefix := fmt.Sprintf("%s[%s] %s: ", theme.received, colorize(ansiDim, timeStr), colorize(ansiGreen, friendUsername))
+		fmt.Fprintln(w, highlightedMessage(prefix, messageText(msg), ansiGreen))
+		if !msg.IsRead {
+			fmt.Fprintf(w, "   Status: Unread\n")
+		} else {
+			fmt.Fprintf(w, "   Status: Read\n")
+		}
+	}
+	
+	if msg.Attachment != "" {
+		fmt.Fprintf(w, "   📎 %s %s\n", attachmentName(msg.Attachment), colorize(ansiDim, msg.Attachment))
+	}
+	if len(msg.Reactions) > 0 {
+		fmt.Fprintf(w, "   %s\n", reactionSummary(msg.Reactions))
+	}
+	if msg.Edited {
+		fmt.Fprintf(w, "   Message ID: %d %s\n", msg.MessageID, colorize(ansiDim, "(edited)"))
+	} else {
+		fmt.Fprintf(w, "   Message ID: %d\n", msg.MessageID)
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 40))
+}
+
+// reactionSummary formats reaction counts such as "👍 2  ❤️ 1", most frequent first
+func reactionSummary(reactions map[string]int) string {
+	emojis := make([]string, 0, len(reactions))
+	for emoji, count := range reactions {
+		if count > 0 {
+			emojis = append(emojis, emoji)
+		}
+	}
+	slices.SortFunc(emojis, func(a, b string) int {
+		if c := cmp.Compare(reactions[b], reactions[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+
+	parts := make([]string, len(emojis))
+	for i, emoji := range emojis {
+		parts[i] = fmt.Sprintf("%s %d", emoji, reactions[emoji])
+	}
+	return strings.Join(parts, "  ")
+}
+
+// humanizeTime describes t relative to now, such as "just now", "5m ago",
+// "3h ago", "yesterday" or "4 days ago", falling back to the full date after a week
+func humanizeTime(t time.Time) string {
+	elapsed := time.Since(t)
+	switch {
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%dm ago", int(elapsed/time.Minute))
+	case elapsed < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(elapsed/time.Hour))
+	case elapsed < 48*time.Hour:
+		return "yesterday"
+	case elapsed < 7*24*time.Hour:
+		return fmt.Sprintf("%d days ago", int(elapsed/(24*time.Hour)))
+	default:
+		return t.Format("Jan 2, 2006 at 3:04 PM")
+	}
+}
+
+// highlightedMessage wraps prefix and text like wrapMessage, colors the text and
+// highlights occurrences of the conversation search term in it
+func highlightedMessage(prefix, text, color string) string {
+	body := wrapMessage(prefix, text)[len(prefix):]
+	if conversationSearch != "" && colorEnabled {
+		body = highlightMatches(body, conversationSearch)
+	}
+	return prefix + colorize(color, body)
+}
+
+// highlightMatches marks every case-insensitive occurrence of term in text using reverse video
+func highlightMatches(text, term string) string {
+	lowerText := strings.ToLower(text)
+	lowerTerm := strings.ToLower(term)
+	// Lowercasing can change byte lengths for some scripts; skip highlighting rather than misalign
+	if lowerTerm == "" || len(lowerText) != len(text) {
+		return text
+	}
+
+	var builder strings.Builder
+	for {
+		index := strings.Index(lowerText, lowerTerm)
+		if index == -1 {
+			builder.WriteString(text)
+			return builder.String()
+		}
+		end := index + len(lowerTerm)
+		builder.WriteString(text[:index])
+		builder.WriteString(ansiReverse + text[index:end] + ansiReverseOff)
+		text, lowerText = text[end:], lowerText[end:]
+	}
+}
+
+// searchConversation prompts for a search term and re-renders the conversation showing
+// only messages that contain it. An empty term returns to the full conversation.
+func searchConversation(token *TokenData, friend *Friend) error {
+	fmt.Print("\nSearch messages (leave empty to show all): ")
+	reader := stdinReader
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("error reading input: %v", err)
+	}
+
+	conversationSearch = strings.TrimSpace(input)
+	conversationPage = 0
+	return fetchConversation(token, friend)
+}
+
+// jumpToMessage prompts for a message ID and shows that message with a few messages of context
+func jumpToMessage(token *TokenData, friend *Friend) error {
+	fmt.Print("\nEnter message ID to jump to: ")
+	reader := stdinReader
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("error reading input: %v", err)
+	}
+
+	messageID, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil {
+		return fmt.Errorf("invalid message ID: please enter a number")
+	}
+
+	conversation, err := getConversation(token, friend)
+	if err != nil {
+		return err
+	}
+	filteredMessages := filterConversation(token, friend, conversation)
+
+	// Locate the message in the fetched set
+	index := -1
+	for i, msg := range filteredMessages {
+		if msg.MessageID == messageID {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return fmt.Errorf("message ID %d not found in your conversation with %s", messageID, friendDisplayName(friend))
+	}
+
+	// Show the target message centered between its neighbours
+	const contextSize = 3
+	first := max(index-contextSize, 0)
+	last := min(index+contextSize, len(filteredMessages)-1)
+
+	friendName := friendDisplayName(friend)
+	fmt.Printf("\n=== Message %d (showing messages %d–%d of %d) ===\n\n", messageID, first+1, last+1, len(filteredMessages))
+	for i := first; i <= last; i++ {
+		if i == index {
+			fmt.Println(">>> Jumped to message:")
 		}
-		
-		fmt.Printf("   Message ID: %d\n", msg.MessageID)
-		fmt.Println(strings.Repeat("-", 40))
+		printMessage(os.Stdout, token, friendName, filteredMessages[i])
 	}
 
-	fmt.Printf("\nEnd of conversation with %s\n", friendUsername)
+	return nil
 }
 
-// handleSendMessage handles the message sending flow
-func handleSendMessage(token *TokenData, friend *Friend) error {
+// messageText returns the text to display for a message, with a placeholder for empty bodies
+func messageText(msg Message) string {
+	if strings.TrimSpace(msg.Message) == "" {
+		if msg.Attachment != "" {
+			return "[attachment]"
+		}
+		return "[empty message]"
+	}
+	return msg.Message
+}
+
+// replyQuoteLength is how many characters of the replied-to message are quoted
+const replyQuoteLength = 80
+
+// handleReply sends a message that starts with a quote of the friend's most recent
+// message. Without any received messages it falls back to a normal message.
+func handleReply(token *TokenData, friend *Friend) error {
+	conversation, err := getConversation(token, friend)
+	if err != nil {
+		return fmt.Errorf("error fetching conversation: %v", err)
+	}
+
+	quote := ""
+	messages := filterConversation(token, friend, conversation)
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Sender != token.UserID {
+			quote = "> " + snippet(messageText(messages[i]), "", replyQuoteLength) + "\n"
+			break
+		}
+	}
+
+	if quote == "" {
+		fmt.Printf("No messages from %s to reply to yet.\n", friend.GetUsername())
+	}
+	return handleSendMessage(token, friend, quote)
+}
+
+// handleSendMessage handles the message sending flow. A non-empty quote is shown
+// before composing and sent ahead of the message.
+func handleSendMessage(token *TokenData, friend *Friend, quote string) error {
 	friendUsername := friend.GetUsername()
 	friendUserID := friend.GetUserID()
 	
 	fmt.Printf("Sending message to: %s\n", friendUsername)
-	fmt.Print("Enter your message: ")
+	if quote != "" {
+		fmt.Print(colorize(ansiDim, quote))
+	}
 	
 	// Read message from user
-	reader := bufio.NewReader(os.Stdin)
-	message, err := reader.ReadString('\n')
+	reader := stdinReader
+	message, err := composeMessage(reader)
 	if err != nil {
 		return fmt.Errorf("error reading message input: %v", err)
 	}
 	
-	message = strings.TrimSpace(message)
 	if message == "" {
 		fmt.Println("Message cannot be empty. Message sending cancelled.")
 		return nil
 	}
+	message = quote + message
+
+	if confirmSend && !confirmRecipient(reader, friend) {
+		fmt.Println("Message not sent.")
+		return nil
+	}
 	
 	// Send the message using the API
-	fmt.Println("📤 Sending message...")
 	err = sendMessageToFriend(token.Token, message, friendUserID)
 	if err != nil {
 		return fmt.Errorf("failed to send message: %v", err)
 	}
 	
 	fmt.Printf("✅ Message sent successfully to %s!\n", friendUsername)
+
+	if !refreshAfterSend {
+		fmt.Println("Press CTRL+R to refresh the conversation.")
+		return nil
+	}
 	
-	// Automatically refresh conversation to show the new message
+	// Automatically refresh conversation to show the new message on the latest page
+	conversationPage = 0
 	fmt.Println("🔄 Refreshing conversation to show your message...")
 	err = fetchConversation(token, friend)
 	if err != nil {
-		fmt.Printf("Error refreshing conversation: %v\n", err)
+		errorf("Error refreshing conversation: %v\n", err)
 	}
 	
 	return nil
 }
 
-// sendMessageToFriend sends a message using the API (from send_message.go logic)
+// sendMessageToFriend sends a message using the API (from send_message.go logic),
+// showing a spinner while the request is in flight
 func sendMessageToFriend(token, message, recipientUID string) error {
-	// Prepare request payload
-	messageReq := MessageRequest{
-		Message:         message,
-		RecipientUserID: recipientUID,
-	}
-
-	jsonData, err := json.Marshal(messageReq)
+	stopSpinner := startSpinner("📤 Sending message...")
+	_, err := NewAPIClient(token).SendMessage(message, recipientUID)
+	stopSpinner()
 	if err != nil {
-		return fmt.Errorf("failed to marshal request: %v", err)
+		rememberFailedMessage(MessageRequest{Message: message, RecipientUserID: recipientUID}, err)
 	}
-
-	// Create HTTP request
-	req, err := http.NewRequest("POST", "https://wasalbackend-production.up.railway.app/auth/send_message", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
-	}
-
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+token)
-
-	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	// Read response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response: %v", err)
-	}
-
-	// Check if request was successful
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
-	}
-
-	// Parse and display response (optional - for debugging)
-	var messageResp MessageResponse
-	err = json.Unmarshal(body, &messageResp)
-	if err != nil {
-		// If parsing fails, just return success since the message was sent
-		return nil
-	}
-
-	return nil
+	return err
 }
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMessageText(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  Message
+		want string
+	}{
+		{"plain text", Message{Message: "hello"}, "hello"},
+		{"empty body", Message{Message: ""}, "[empty message]"},
+		{"whitespace only", Message{Message: " \n\t"}, "[empty message]"},
+		{"attachment without text", Message{Attachment: "photo.png"}, "[attachment]"},
+		{"attachment with text", Message{Message: "look", Attachment: "photo.png"}, "look"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := messageText(tt.msg); got != tt.want {
+				t.Errorf("messageText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderConversationEmptyMessage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	token := &TokenData{UserID: "1"}
+	friend := &Friend{FriendID: "2", FriendUsername: "alice"}
+	conversation := &ConversationResponse{
+		Conversation: []Message{
+			{MessageID: 1, Sender: "2", Recipient: "1", Message: "", Timestamp: "2026-01-01T10:00:00"},
+		},
+		TotalMessages: 1,
+	}
+
+	var buf bytes.Buffer
+	renderConversation(&buf, token, friend, conversation)
+	if !strings.Contains(buf.String(), "[empty message]") {
+		t.Errorf("rendered conversation has no placeholder:\n%s", buf.String())
+	}
+}
+
+func TestHumanizeTime(t *testing.T) {
+	now := time.Now()
+	weekAgo := now.Add(-7 * 24 * time.Hour)
+
+	tests := []struct {
+		name string
+		ago  time.Duration
+		want string
+	}{
+		{"in the future", -time.Minute, "just now"},
+		{"now", 0, "just now"},
+		{"under a minute", time.Minute - time.Second, "just now"},
+		{"one minute", time.Minute, "1m ago"},
+		{"under an hour", time.Hour - time.Second, "59m ago"},
+		{"one hour", time.Hour, "1h ago"},
+		{"under a day", 24*time.Hour - time.Second, "23h ago"},
+		{"one day", 24 * time.Hour, "yesterday"},
+		{"under two days", 48*time.Hour - time.Second, "yesterday"},
+		{"two days", 48 * time.Hour, "2 days ago"},
+		{"under a week", 7*24*time.Hour - time.Second, "6 days ago"},
+		{"one week", 7 * 24 * time.Hour, weekAgo.Format("Jan 2, 2006 at 3:04 PM")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := humanizeTime(now.Add(-tt.ago)); got != tt.want {
+				t.Errorf("humanizeTime(now - %s) = %q, want %q", tt.ago, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterConversationDuplicates(t *testing.T) {
+	token := &TokenData{UserID: "1"}
+	friend := &Friend{FriendID: "2", FriendUsername: "alice"}
+	conversation := &ConversationResponse{Conversation: []Message{
+		{MessageID: 3, Sender: "2", Recipient: "1", Message: "third", Timestamp: "2026-01-01 10:02:00"},
+		{MessageID: 1, Sender: "1", Recipient: "2", Message: "first", Timestamp: "2026-01-01 10:00:00"},
+		{MessageID: 2, Sender: "2", Recipient: "1", Message: "second", Timestamp: "2026-01-01 10:01:00"},
+		{MessageID: 2, Sender: "2", Recipient: "1", Message: "second", Timestamp: "2026-01-01 10:01:00", IsRead: true},
+		{MessageID: 1, Sender: "1", Recipient: "2", Message: "first, edited", Timestamp: "2026-01-01 10:00:00", Edited: true},
+		{MessageID: 3, Sender: "2", Recipient: "1", Message: "third", Timestamp: "2026-01-01 10:02:00"},
+		{MessageID: 2, Sender: "2", Recipient: "1", Message: "second", Timestamp: "2026-01-01 10:01:00"},
+		{MessageID: 4, Sender: "3", Recipient: "1", Message: "someone else", Timestamp: "2026-01-01 10:03:00"},
+	}}
+
+	got := filterConversation(token, friend, conversation)
+
+	var ids []int
+	for _, msg := range got {
+		ids = append(ids, msg.MessageID)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 2, 3}) {
+		t.Fatalf("message IDs = %v, want [1 2 3]", ids)
+	}
+	if !got[0].Edited || got[0].Message != "first, edited" {
+		t.Errorf("message 1 = %+v, want the edited copy", got[0])
+	}
+	if !got[1].IsRead {
+		t.Errorf("message 2 = %+v, want the read copy even though an unread one came later", got[1])
+	}
+}
+
+func TestMoreCompleteMessage(t *testing.T) {
+	tests := []struct {
+		name            string
+		kept, duplicate Message
+		want            Message
+	}{
+		{"read wins", Message{}, Message{IsRead: true}, Message{IsRead: true}},
+		{"read kept", Message{IsRead: true}, Message{Edited: true}, Message{IsRead: true}},
+		{"edited wins", Message{}, Message{Edited: true}, Message{Edited: true}},
+		{"first copy kept on a tie", Message{Message: "a"}, Message{Message: "b"}, Message{Message: "a"}},
+	}
+
+	for _, tt := range tests {
+		if got := moreCompleteMessage(tt.kept, tt.duplicate); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// runReceiveOnce runs the receive command with args, stdin and stdout replaced by
+// pipes so neither is a terminal, and returns what it printed
+func runReceiveOnce(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	savedArgs, savedStdin, savedStdout, savedReader := os.Args, os.Stdin, os.Stdout, stdinReader
+	t.Cleanup(func() {
+		os.Args, os.Stdin, os.Stdout, stdinReader = savedArgs, savedStdin, savedStdout, savedReader
+	})
+
+	stdinRead, stdinWrite, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdinWrite.Close()
+	os.Stdin = stdinRead
+	stdinReader = bufio.NewReader(stdinRead)
+
+	stdoutRead, stdoutWrite, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = stdoutWrite
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(stdoutRead)
+		output <- string(data)
+	}()
+
+	os.Args = append([]string{"chat", "receive"}, args...)
+	runErr := receive_message()
+	stdoutWrite.Close()
+	return <-output, runErr
+}
+
+func TestReceiveOnce(t *testing.T) {
+	var paths []string
+	newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/auth/get_friends":
+			writeJSON(t, w, http.StatusOK, FriendsAPIResponse{Friends: []Friend{{FriendID: "2", FriendUsername: "alice"}}})
+		case "/auth/conversation/2":
+			writeJSON(t, w, http.StatusOK, ConversationResponse{Conversation: []Message{
+				{MessageID: 1, Sender: "2", Recipient: "1", Message: "unread hello", Timestamp: "2026-01-01 10:00:00"},
+			}, TotalMessages: 1})
+		default:
+			writeJSON(t, w, http.StatusOK, map[string]string{"message": "ok"})
+		}
+	})
+	if err := saveToken(TokenData{Token: "tok", UserID: "1", Username: "me"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := queueMessage(MessageRequest{Message: "queued", RecipientUserID: "2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := runReceiveOnce(t, "--once", "--to-username", "alice")
+	if err != nil {
+		t.Fatalf("receive --once: %v", err)
+	}
+	if !strings.Contains(output, "unread hello") {
+		t.Errorf("conversation not printed:\n%s", output)
+	}
+	for _, path := range paths {
+		if path != "/auth/get_friends" && path != "/auth/conversation/2" {
+			t.Errorf("receive --once requested %s; it should only read", path)
+		}
+	}
+
+	// Without a recipient it fails instead of prompting on the non-terminal stdin
+	paths = nil
+	if _, err := runReceiveOnce(t, "--exit-on-read"); err == nil || !strings.Contains(err.Error(), "--to-username") {
+		t.Errorf("receive --exit-on-read without a recipient: error = %v", err)
+	}
+}
+
+func TestSinceUsesMessageTimeZone(t *testing.T) {
+	// Off UTC, a --since date must still line up with the message timestamps
+	saved := time.Local
+	time.Local = time.FixedZone("UTC+5", 5*60*60)
+	t.Cleanup(func() { time.Local = saved })
+
+	since, err := parseSince("2026-01-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := []Message{
+		{MessageID: 1, Timestamp: "2025-12-31 23:59:59"},
+		{MessageID: 2, Timestamp: "2026-01-01 00:00:00"},
+		{MessageID: 3, Timestamp: "2026-01-01 03:00:00"},
+		{MessageID: 4, Timestamp: "not a time"},
+	}
+
+	var ids []int
+	for _, msg := range sinceMessages(messages, since) {
+		ids = append(ids, msg.MessageID)
+	}
+	if !reflect.DeepEqual(ids, []int{2, 3, 4}) {
+		t.Errorf("messages since 2026-01-01 = %v, want [2 3 4]", ids)
+	}
+}
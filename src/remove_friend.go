@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+)
+
+// RemoveFriendRequest represents the request payload for removing a friend
+type RemoveFriendRequest struct {
+	FriendUserID string `json:"friend_user_id"`
+}
+
+// removeFriendCommand implements the remove command
+func removeFriendCommand() error {
+	token, err := requireToken()
+	if err != nil {
+		return err
+	}
+
+	friends, err := fetchFriendsFromAPI(token.Token)
+	if err != nil {
+		return fmt.Errorf("error fetching friends: %v", err)
+	}
+
+	if len(friends.Friends) == 0 {
+		fmt.Println("No friends found in your friends list.")
+		return nil
+	}
+
+	selectedFriend, err := selectFriendWithPrompt(friends, "Enter the number of the friend you want to remove: ")
+	if errors.Is(err, errSelectionCancelled) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error selecting friend: %v", err)
+	}
+
+	fmt.Printf("Remove %s (ID: %s) from your friends? [y/N]: ", selectedFriend.GetUsername(), selectedFriend.GetUserID())
+	reader := stdinReader
+	input, _ := reader.ReadString('\n')
+	input = strings.ToLower(strings.TrimSpace(input))
+	if input != "y" && input != "yes" {
+		fmt.Println("Friend not removed.")
+		return nil
+	}
+
+	err = removeFriend(token, selectedFriend.GetUserID())
+	switch {
+	case isAPIStatus(err, http.StatusNotFound):
+		// The server no longer knows this friendship; still clean up locally
+		fmt.Printf("%s was already removed on the server.\n", selectedFriend.GetUsername())
+	case err != nil:
+		return fmt.Errorf("error removing friend: %v", err)
+	default:
+		fmt.Printf("✓ Removed %s from your friends.\n", selectedFriend.GetUsername())
+	}
+
+	if err := removeFriendFromFile(selectedFriend.GetUserID()); err != nil {
+		errorf("Warning: %v\n", err)
+	}
+
+	return nil
+}
+
+// removeFriend asks the API to remove the friendship with friendUserID
+func removeFriend(token *TokenData, friendUserID string) error {
+	jsonData, err := json.Marshal(RemoveFriendRequest{FriendUserID: friendUserID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(appContext(), "POST", apiURL("/auth/remove_friend"), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := newHTTPClient()
+	resp, err := doRequest(client, req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %v", err)
+	}
+
+	if !isSuccess(resp.StatusCode) {
+		return newAPIError(resp, body)
+	}
+
+	return nil
+}
+
+// removeFriendFromFile strips a friend from the local friends.json, if it exists
+func removeFriendFromFile(friendUserID string) error {
+	friends, err := readFriendsForReceiveMessage()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var kept []Friend
+	for _, friend := range friends.Friends {
+		if friend.GetUserID() != friendUserID {
+			kept = append(kept, friend)
+		}
+	}
+	if len(kept) == len(friends.Friends) {
+		return nil
+	}
+
+	friends.Friends = kept
+	return saveFriendsFile(friends)
+}
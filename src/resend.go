@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+)
+
+// lastMessageFile holds the most recent message that failed to send
+const lastMessageFile = "last_message.json"
+
+// FailedMessage is a message that could not be sent, kept so it can be resent
+type FailedMessage struct {
+	MessageRequest
+	FailedAt string `json:"failed_at"`
+	Error    string `json:"error"`
+}
+
+// rememberFailedMessage saves a message that failed to send so `resend` can replay it.
+// When the backend could not be reached the message is queued in the outbox instead.
+func rememberFailedMessage(request MessageRequest, sendErr error) {
+	if isOfflineError(sendErr) {
+		if err := queueMessage(request); err != nil {
+			errorf("Warning: could not queue the message: %v\n", err)
+			return
+		}
+		errorf("You appear to be offline; the message was queued and will be sent by `flush` or your next send/receive.\n")
+		return
+	}
+
+	failed := FailedMessage{
+		MessageRequest: request,
+		FailedAt:       time.Now().Format(time.RFC3339),
+		Error:          sendErr.Error(),
+	}
+
+	jsonData, err := json.MarshalIndent(failed, "", "  ")
+	if err == nil {
+		err = writeStateFile(lastMessageFile, jsonData)
+	}
+	if err != nil {
+		errorf("Warning: could not save the failed message: %v\n", err)
+		return
+	}
+	errorf("The message was saved; run `resend` to try again.\n")
+}
+
+// resendMessage implements the resend command: it replays the last failed message
+func resendMessage() error {
+	data, err := readStateFile(lastMessageFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		infoln("No failed message to resend.")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", lastMessageFile, err)
+	}
+
+	var failed FailedMessage
+	if err := json.Unmarshal(data, &failed); err != nil {
+		return fmt.Errorf("failed to parse %s: %v", lastMessageFile, err)
+	}
+
+	token, err := requireToken()
+	if err != nil {
+		return err
+	}
+
+	infof("Resending message from %s to %s:\n%s\n", failed.FailedAt, failed.RecipientUserID, failed.Message)
+	if err := sendMessage(token.Token, failed.MessageRequest); err != nil {
+		// An offline failure moved the message to the outbox
+		if isOfflineError(err) {
+			removeStateFile(lastMessageFile)
+		}
+		return err
+	}
+
+	if err := removeStateFile(lastMessageFile); err != nil {
+		errorf("Warning: %v\n", err)
+	}
+	infoln("Message resent successfully!")
+	return nil
+}
@@ -7,15 +7,15 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"path/filepath"
-	"time"
 )
 
 // All types are now defined in types.go
 
-// FriendRequestPayload represents the request payload for sending friend request
+// FriendRequestPayload represents the request payload for sending friend request.
+// The recipient is identified by either Username or RecipientUserID.
 type FriendRequestPayload struct {
-	Username string `json:"username"`
+	Username        string `json:"username,omitempty"`
+	RecipientUserID string `json:"recipient_user_id,omitempty"`
 }
 
 // FriendRequestResponse represents the API response for friend request
@@ -30,23 +30,22 @@ type FriendRequestResponse struct {
 var authToken string
 
 func friend() error {
-	// Get config directory path
-	homeDir, err := os.UserHomeDir()
+	// Read token from file
+	token, err := requireToken()
 	if err != nil {
-		fmt.Printf("Error getting home directory: %v\n", err)
-		os.Exit(1)
+		return err
 	}
+	authToken = token.Token
 
-	configDir := filepath.Join(homeDir, ".config", "chat_app")
-	tokenPath := filepath.Join(configDir, "token.json")
+	// With --id the request is sent straight to a user ID, skipping the name lookup
+	if userID, ok := flagValue(os.Args[2:], "--id"); ok {
+		return sendFriendRequestToID(userID, authToken)
+	}
 
-	// Read token from file
-	token, err := readToken(tokenPath)
-	if err != nil {
-		fmt.Printf("Error reading token: %v\n", err)
-		os.Exit(1)
+	// With a username argument look the user up once and exit, for use in scripts
+	if username := firstArg(os.Args[2:], "--id"); username != "" {
+		return lookupUser(username, authToken)
 	}
-	authToken = token.Token
 
 	fmt.Println("Chat App - User Search")
 	fmt.Println("Commands:")
@@ -58,10 +57,9 @@ func friend() error {
 	for {
 		fmt.Print("\nEnter username to search (or 'quit' to exit): ")
 		
-		var input string
-		fmt.Scanln(&input)
+		input, err := readLine()
 
-		if input == "quit" || input == "exit" {
+		if input == "quit" || input == "exit" || err != nil {
 			fmt.Println("Goodbye!")
 			break
 		}
@@ -74,7 +72,7 @@ func friend() error {
 		// Search for user via API
 		userInfo, err := searchUser(input, authToken)
 		if err != nil {
-			fmt.Printf("Error searching user: %v\n", err)
+			errorf("Error searching user: %v\n", err)
 			continue
 		}
 
@@ -85,13 +83,12 @@ func friend() error {
 		
 		// Ask if user wants to send friend request
 		fmt.Printf("\nDo you want to send a friend request to %s? (y/n): ", userInfo.UserData.Username)
-		var choice string
-		fmt.Scanln(&choice)
+		choice, _ := readLine()
 		
 		if choice == "y" || choice == "Y" || choice == "yes" || choice == "Yes" || choice == "YES" {
 			err := sendFriendRequest(userInfo.UserData.Username, authToken)
 			if err != nil {
-				fmt.Printf("❌ Error sending friend request: %v\n", err)
+				errorf("❌ Error sending friend request: %v\n", err)
 			}
 		} else {
 			fmt.Println("Friend request not sent.")
@@ -101,73 +98,49 @@ func friend() error {
 	return nil
 }
 
-func readToken(tokenPath string) (*TokenData, error) {
-	file, err := os.Open(tokenPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open token file: %w", err)
-	}
-	defer file.Close()
-
-	var tokenData TokenData
-	decoder := json.NewDecoder(file)
-	err = decoder.Decode(&tokenData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode token JSON: %w", err)
-	}
-
-	return &tokenData, nil
-}
-
 func searchUser(username, token string) (*APIResponse, error) {
-	// Create HTTP client
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
+	return NewAPIClient(token).SearchUser(username)
+}
 
-	// Create request
-	req, err := http.NewRequest("GET", "https://wasalbackend-production.up.railway.app/auth/search_user", nil)
+// lookupUser searches for username once and prints the user found, as JSON with --json
+func lookupUser(username, token string) error {
+	userInfo, err := searchUser(username, token)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return err
 	}
 
-	// Set headers
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("username", username)
-
-	// Make request
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+	if jsonOutput {
+		return printJSON(userInfo.UserData)
 	}
-	defer resp.Body.Close()
+	fmt.Printf("✓ User found: %s (ID: %s)\n", userInfo.UserData.Username, userInfo.UserData.UserID)
+	return nil
+}
 
-	// Check status code
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+// sendFriendRequestToID confirms and sends a friend request to a user ID
+func sendFriendRequestToID(userID, token string) error {
+	if userID == "" {
+		return fmt.Errorf("--id requires a user ID")
 	}
 
-	// Parse response
-	var apiResponse APIResponse
-	decoder := json.NewDecoder(resp.Body)
-	err = decoder.Decode(&apiResponse)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode API response: %w", err)
-	}
+	fmt.Printf("\nDo you want to send a friend request to user ID %s? (y/n): ", userID)
+	choice, _ := readLine()
 
-	return &apiResponse, nil
+	if choice == "y" || choice == "Y" || choice == "yes" || choice == "Yes" || choice == "YES" {
+		return sendFriendRequestPayload(FriendRequestPayload{RecipientUserID: userID}, "user ID "+userID, token)
+	}
+	fmt.Println("Friend request not sent.")
+	return nil
 }
 
 func sendFriendRequest(username, token string) error {
-	// Create HTTP client
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
+	return sendFriendRequestPayload(FriendRequestPayload{Username: username}, username, token)
+}
 
-	// Create request payload
-	payload := FriendRequestPayload{
-		Username: username,
-	}
+// sendFriendRequestPayload sends a friend request to the recipient in payload,
+// using recipient in the messages shown to the user
+func sendFriendRequestPayload(payload FriendRequestPayload, recipient, token string) error {
+	// Create HTTP client
+	client := newHTTPClient()
 
 	// Convert payload to JSON
 	jsonData, err := json.Marshal(payload)
@@ -176,7 +149,7 @@ func sendFriendRequest(username, token string) error {
 	}
 
 	// Create request
-	req, err := http.NewRequest("POST", "https://wasalbackend-production.up.railway.app/auth/send_friend_request", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(appContext(), "POST", apiURL("/auth/send_friend_request"), bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -186,7 +159,7 @@ func sendFriendRequest(username, token string) error {
 	req.Header.Set("Content-Type", "application/json")
 
 	// Make request
-	resp, err := client.Do(req)
+	resp, err := doRequest(client, req)
 	if err != nil {
 		return fmt.Errorf("failed to make request: %w", err)
 	}
@@ -199,8 +172,8 @@ func sendFriendRequest(username, token string) error {
 	}
 
 	// Check status code
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	if !isSuccess(resp.StatusCode) {
+		return newAPIError(resp, body)
 	}
 
 	// Parse response
@@ -211,7 +184,7 @@ func sendFriendRequest(username, token string) error {
 	}
 
 	// Display success message
-	fmt.Printf("✓ Friend request sent successfully to %s!\n", username)
+	fmt.Printf("✓ Friend request sent successfully to %s!\n", recipient)
 	fmt.Printf("  Message: %s\n", friendResponse.Message)
 	if friendResponse.RequestID != 0 {
 		fmt.Printf("  Request ID: %d\n", friendResponse.RequestID)
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MessageSearchAPIResponse represents the API response for the search_messages endpoint
+type MessageSearchAPIResponse struct {
+	Results      []Message `json:"results"`
+	TotalResults int       `json:"total_results"`
+}
+
+// MessageSearchMatch represents a single message matching a search
+type MessageSearchMatch struct {
+	FriendID       string `json:"friend_id"`
+	FriendUsername string `json:"friend_username"`
+	MessageID      int    `json:"message_id"`
+	Direction      string `json:"direction"`
+	Timestamp      string `json:"timestamp"`
+	Snippet        string `json:"snippet"`
+}
+
+// MessageSearchResult is the output of search-messages
+type MessageSearchResult struct {
+	Query        string               `json:"query"`
+	TotalMatches int                  `json:"total_matches"`
+	Matches      []MessageSearchMatch `json:"matches"`
+}
+
+// searchMessages implements the search-messages command
+func searchMessages() error {
+	if len(os.Args) < 3 || strings.HasPrefix(os.Args[2], "--") {
+		return fmt.Errorf("usage: go run main.go search-messages <term> [--limit N] [--json]")
+	}
+
+	term := os.Args[2]
+	args := os.Args[3:]
+
+	limit := 50
+	if value, ok := flagValue(args, "--limit"); ok {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return fmt.Errorf("invalid --limit %q: must be a positive number", value)
+		}
+		limit = parsed
+	}
+
+	token, err := requireToken()
+	if err != nil {
+		return err
+	}
+
+	friends, err := fetchFriendsFromAPI(token.Token)
+	if err != nil {
+		return fmt.Errorf("error fetching friends: %v", err)
+	}
+
+	// Prefer the server-side search and fall back to scanning each conversation
+	matches, supported, err := searchMessagesAPI(token, friends, term)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		matches = searchMessagesLocally(token, friends, term)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return strings.ToLower(matches[i].FriendUsername) < strings.ToLower(matches[j].FriendUsername)
+	})
+
+	result := MessageSearchResult{
+		Query:        term,
+		TotalMatches: len(matches),
+		Matches:      matches,
+	}
+	if len(result.Matches) > limit {
+		result.Matches = result.Matches[:limit]
+	}
+
+	if jsonOutput {
+		jsonData, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode results: %v", err)
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	displayMessageSearchResult(&result)
+	return nil
+}
+
+// searchMessagesAPI queries the backend search endpoint.
+// supported is false when the backend does not provide the endpoint.
+func searchMessagesAPI(token *TokenData, friends *FriendsData, term string) (matches []MessageSearchMatch, supported bool, err error) {
+	searchURL := apiURL("/auth/search_messages?q=") + url.QueryEscape(term)
+
+	req, err := http.NewRequestWithContext(appContext(), "GET", searchURL, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+
+	client := newHTTPClient()
+	resp, err := doRequest(client, req)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed {
+		return nil, false, nil
+	}
+	if !isSuccess(resp.StatusCode) {
+		return nil, false, newAPIError(resp, body)
+	}
+
+	var apiResponse MessageSearchAPIResponse
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return nil, false, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	// Attribute each result to the friend on the other side of it
+	friendsByID := make(map[string]*Friend)
+	for i := range friends.Friends {
+		friendsByID[friends.Friends[i].GetUserID()] = &friends.Friends[i]
+	}
+	for _, msg := range apiResponse.Results {
+		otherID := msg.Sender
+		if otherID == token.UserID {
+			otherID = msg.Recipient
+		}
+		friend, ok := friendsByID[otherID]
+		if !ok {
+			continue
+		}
+		matches = append(matches, newMessageSearchMatch(token, friend, msg, term))
+	}
+
+	return matches, true, nil
+}
+
+// searchMessagesLocally fetches every conversation and searches them client-side
+func searchMessagesLocally(token *TokenData, friends *FriendsData, term string) []MessageSearchMatch {
+	conversations := fetchAllConversations(token, friends)
+
+	var matches []MessageSearchMatch
+	for i := range friends.Friends {
+		friend := &friends.Friends[i]
+		conversation, ok := conversations[friend.GetUserID()]
+		if !ok {
+			continue
+		}
+		for _, msg := range filterConversation(token, friend, conversation) {
+			if containsFold(msg.Message, term) {
+				matches = append(matches, newMessageSearchMatch(token, friend, msg, term))
+			}
+		}
+	}
+
+	return matches
+}
+
+// newMessageSearchMatch builds a search match for msg in the conversation with friend
+func newMessageSearchMatch(token *TokenData, friend *Friend, msg Message, term string) MessageSearchMatch {
+	direction := "received"
+	if msg.Sender == token.UserID {
+		direction = "sent"
+	}
+	return MessageSearchMatch{
+		FriendID:       friend.GetUserID(),
+		FriendUsername: friend.GetUsername(),
+		MessageID:      msg.MessageID,
+		Direction:      direction,
+		Timestamp:      msg.Timestamp,
+		Snippet:        snippet(msg.Message, term, 30),
+	}
+}
+
+// displayMessageSearchResult prints search matches grouped by friend
+func displayMessageSearchResult(result *MessageSearchResult) {
+	if result.TotalMatches == 0 {
+		fmt.Printf("No messages found containing %q.\n", result.Query)
+		return
+	}
+
+	fmt.Printf("\n=== Messages containing %q (%d matches) ===\n", result.Query, result.TotalMatches)
+	if len(result.Matches) < result.TotalMatches {
+		fmt.Printf("Showing the first %d matches (use --limit to see more)\n", len(result.Matches))
+	}
+
+	currentFriend := ""
+	for _, match := range result.Matches {
+		if match.FriendID != currentFriend {
+			currentFriend = match.FriendID
+			fmt.Printf("\n--- %s ---\n", match.FriendUsername)
+		}
+
+		who := match.FriendUsername
+		if match.Direction == "sent" {
+			who = "You"
+		}
+		fmt.Printf("[%s] %s: %s (Message ID: %d)\n", match.Timestamp, who, match.Snippet, match.MessageID)
+	}
+}
+
+// containsFold reports whether substr is within s, ignoring case
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// indexFold returns the rune index of the first match of term in runes, ignoring
+// case, or -1. It compares rune windows rather than lowercasing both strings,
+// whose byte offsets differ when lowercasing changes a character's encoded length.
+func indexFold(runes, term []rune) int {
+	if len(term) == 0 {
+		return -1
+	}
+	for i := 0; i+len(term) <= len(runes); i++ {
+		if strings.EqualFold(string(runes[i:i+len(term)]), string(term)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// snippet returns the part of text around the first match of term,
+// keeping up to radius characters on each side
+func snippet(text, term string, radius int) string {
+	text = strings.Join(strings.Fields(text), " ")
+
+	runes := []rune(text)
+	termRunes := []rune(term)
+	matchStart := max(indexFold(runes, termRunes), 0)
+	start := max(matchStart-radius, 0)
+	end := min(matchStart+len(termRunes)+radius, len(runes))
+
+	result := string(runes[start:end])
+	if start > 0 {
+		result = "…" + result
+	}
+	if end < len(runes) {
+		result += "…"
+	}
+	return result
+}
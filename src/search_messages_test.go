@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestSnippet(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		term   string
+		radius int
+		want   string
+	}{
+		{"match in middle", "the quick brown fox jumps", "brown", 4, "…ick brown fox…"},
+		{"case-insensitive", "Hello World", "WORLD", 2, "…o World"},
+		{"no match starts at beginning", "hello world", "zzz", 3, "hello …"},
+		{"empty term", "hello", "", 10, "hello"},
+		{"collapses whitespace", "a\n\n  b", "b", 5, "a b"},
+		// Lowercasing Ⱥ (2 bytes) gives ⱥ (3 bytes), so byte offsets of the two strings differ
+		{"lowercase changes byte length", "ȺȺȺȺȺȺ ok", "ok", 2, "…Ⱥ ok"},
+		{"multibyte term", "Grüße aus München", "MÜNCHEN", 1, "… München"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := snippet(tt.text, tt.term, tt.radius); got != tt.want {
+				t.Errorf("snippet(%q, %q, %d) = %q, want %q", tt.text, tt.term, tt.radius, got, tt.want)
+			}
+		})
+	}
+}
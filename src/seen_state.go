@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// seenSaveInterval throttles how often the seen-message state is written to disk
+const seenSaveInterval = 5 * time.Second
+
+// SeenState represents seen.json, the last message ID seen per conversation keyed by friend ID
+type SeenState struct {
+	LastSeen map[string]int `json:"last_seen"`
+}
+
+// seenTracker keeps the seen-message state in memory and persists it at most once per seenSaveInterval
+type seenTracker struct {
+	mu       sync.Mutex
+	loaded   bool
+	state    SeenState
+	dirty    bool
+	lastSave time.Time
+}
+
+var seenMessages seenTracker
+
+// load reads seen.json on first use; the caller must hold t.mu
+func (t *seenTracker) load() {
+	if t.loaded {
+		return
+	}
+	t.loaded = true
+	t.state.LastSeen = make(map[string]int)
+
+	data, err := readStateFile("seen.json")
+	if err != nil {
+		if !os.IsNotExist(err) {
+			errorf("Warning: failed to read seen state: %v\n", err)
+		}
+		return
+	}
+	if err := json.Unmarshal(data, &t.state); err != nil {
+		errorf("Warning: failed to parse seen state: %v\n", err)
+	}
+	if t.state.LastSeen == nil {
+		t.state.LastSeen = make(map[string]int)
+	}
+}
+
+// lastSeen returns the last message ID seen in the conversation with friendID, or 0
+func (t *seenTracker) lastSeen(friendID string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.load()
+	return t.state.LastSeen[friendID]
+}
+
+// markSeen records messageID as seen and saves the state if the throttle allows
+func (t *seenTracker) markSeen(friendID string, messageID int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.load()
+
+	if messageID <= t.state.LastSeen[friendID] {
+		return
+	}
+	t.state.LastSeen[friendID] = messageID
+	t.dirty = true
+
+	if time.Since(t.lastSave) >= seenSaveInterval {
+		t.save()
+	}
+}
+
+// flush writes any unsaved state immediately
+func (t *seenTracker) flush() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.dirty {
+		t.save()
+	}
+}
+
+// save writes the state to seen.json; the caller must hold t.mu
+func (t *seenTracker) save() {
+	jsonData, err := json.MarshalIndent(t.state, "", "  ")
+	if err != nil {
+		errorf("Warning: failed to encode seen state: %v\n", err)
+		return
+	}
+	if err := writeStateFile("seen.json", jsonData); err != nil {
+		errorf("Warning: failed to save seen state: %v\n", err)
+		return
+	}
+	t.dirty = false
+	t.lastSave = time.Now()
+}
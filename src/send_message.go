@@ -1,230 +1,274 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
+	"bufio"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
+	"io/fs"
 	"os"
 	"path/filepath"
-	"strconv"
+	"strings"
 )
 
+// composeEnd is the line that finishes a multi-line message
+const composeEnd = "."
+
+// confirmSend asks "Send to <friend>?" before a message is sent (--confirm or confirm_send in config)
+var confirmSend bool
+
 func send_message() error {
-	// Check if message argument is provided
-	if len(os.Args) < 3 {
-		fmt.Println("Usage: go run main.go send \"Your message here\"")
-		os.Exit(1)
+	// Without a message argument the message is composed after picking a friend
+	message := firstArg(os.Args[2:], "--attach", "--to-username", "--to-id", "--sort")
+	attachPath, attaching := flagValue(os.Args[2:], "--attach")
+	if attaching {
+		if err := checkAttachment(attachPath); err != nil {
+			errorf("Error: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
-	message := os.Args[2]
+	confirmSend = hasFlag(os.Args[2:], "--confirm") || loadConfig().ConfirmSend
 
 	// Read token from config file
-	token, err := readTokenFromConfig()
+	token, err := requireToken()
 	if err != nil {
-		fmt.Printf("Error reading token: %v\n", err)
+		errorf("Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Fetch friends from API instead of local file
-	friends, err := fetchFriendsFromAPI(token.Token)
+	// Deliver messages queued while offline before sending a new one
+	autoFlushOutbox(token.Token)
+
+	// Fetch friends from API, or from the cached friends.json with --offline
+	friends, err := loadFriends(token.Token, hasFlag(os.Args[2:], "--offline"))
 	if err != nil {
-		fmt.Printf("Error fetching friends: %v\n", err)
+		errorf("Error fetching friends: %v\n", err)
 		os.Exit(1)
 	}
 
 	// Check if friends list is empty
 	if len(friends.Friends) == 0 {
-		fmt.Println("No friends found in your friends list.")
+		errorf("No friends found in your friends list.\n")
 		os.Exit(1)
 	}
 
-	// Display friends and ask user to select
-	selectedFriend, err := selectFriend(friends)
+	if err := applyFriendSort(token, friends.Friends, os.Args[2:]); err != nil {
+		errorf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Use the recipient given with --to-username or --to-id, or ask the user to pick one
+	selectedFriend, err := recipientFromFlags(friends, os.Args[2:])
+	if err == nil && selectedFriend == nil {
+		selectedFriend, err = selectFriend(friends)
+	}
+	if errors.Is(err, errSelectionCancelled) {
+		return nil
+	}
 	if err != nil {
-		fmt.Printf("Error selecting friend: %v\n", err)
+		errorf("Error selecting friend: %v\n", err)
 		os.Exit(1)
 	}
 
+	reader := stdinReader
+	if message == "" && !attaching {
+		promptf("Composing message to %s\n", selectedFriend.GetUsername())
+		message, err = composeMessage(reader)
+		if err != nil {
+			errorf("Error reading message: %v\n", err)
+			os.Exit(1)
+		}
+		if message == "" {
+			errorf("Message cannot be empty. Message sending cancelled.\n")
+			os.Exit(1)
+		}
+	}
+
+	if confirmSend && !confirmRecipient(reader, selectedFriend) {
+		infoln("Message not sent.")
+		return nil
+	}
+
 	// Send message to selected friend using the appropriate ID field
-	recipientID := selectedFriend.GetUserID()
-	err = sendMessage(token.Token, message, recipientID)
+	request := MessageRequest{Message: message, RecipientUserID: selectedFriend.GetUserID()}
+	if attaching {
+		infof("📎 Uploading %s...\n", filepath.Base(attachPath))
+		upload, err := NewAPIClient(token.Token).UploadFile(attachPath)
+		if err != nil {
+			errorf("Error uploading attachment: %v\n", err)
+			os.Exit(1)
+		}
+		request.Attachment = upload.URL
+	}
+	err = sendMessage(token.Token, request)
 	if err != nil {
-		fmt.Printf("Error sending message: %v\n", err)
+		errorf("Error sending message: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Message sent successfully to %s!\n", selectedFriend.GetUsername())
+	infof("Message sent successfully to %s!\n", selectedFriend.GetUsername())
 	return nil
 }
 
-// readTokenFromConfig reads the token from ~/.config/chat_app/token.json
-func readTokenFromConfig() (*TokenData, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %v", err)
-	}
+// composeMessage reads a message of one or more lines from reader. Input ends at
+// a line containing only composeEnd or at end of input (CTRL+D).
+func composeMessage(reader *bufio.Reader) (string, error) {
+	promptf("Enter your message (finish with a line containing only %q or CTRL+D):\n", composeEnd)
 
-	tokenPath := filepath.Join(homeDir, ".config", "chat_app", "token.json")
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", err
+		}
 
-	file, err := os.Open(tokenPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open token file: %v", err)
+		line = strings.TrimRight(line, "\r\n")
+		if line == composeEnd {
+			break
+		}
+		if line != "" || err == nil {
+			lines = append(lines, line)
+		}
+		if err == io.EOF {
+			promptf("\n")
+			break
+		}
 	}
-	defer file.Close()
 
-	data, err := io.ReadAll(file)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read token file: %v", err)
-	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
 
-	var tokenData TokenData
-	err = json.Unmarshal(data, &tokenData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse token file: %v", err)
+// confirmRecipient asks whether to send to friend, defaulting to no
+func confirmRecipient(reader *bufio.Reader, friend *Friend) bool {
+	promptf("Send to %s (ID: %s)? [y/N]: ", friend.GetUsername(), friend.GetUserID())
+	answer, err := reader.ReadString('\n')
+	if err != nil && answer == "" {
+		return false
 	}
-
-	return &tokenData, nil
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
 }
 
 // fetchFriendsFromAPI fetches the friends list from the API
 func fetchFriendsFromAPI(token string) (*FriendsData, error) {
-	// Create HTTP request
-	req, err := http.NewRequest("GET", "https://wasalbackend-production.up.railway.app/auth/get_friends", nil)
+	friendsData, err := NewAPIClient(token).GetFriends()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
+		return nil, err
 	}
 
-	// Set headers
-	req.Header.Set("Authorization", "Bearer "+token)
-
-	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %v", err)
+	// Keep friends added locally that the server does not list yet, then cache
+	// the union so it can be used with --offline
+	friendsData = mergeLocalFriends(friendsData)
+	if err := saveFriendsFile(friendsData); err != nil {
+		errorf("Warning: could not cache friends list: %v\n", err)
 	}
-	defer resp.Body.Close()
 
-	// Read response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %v", err)
+	return friendsData, nil
+}
+
+// loadFriends returns the friends list from the API, or from the cached
+// friends.json when offline is set
+func loadFriends(token string, offline bool) (*FriendsData, error) {
+	if !offline {
+		return fetchFriendsFromAPI(token)
 	}
 
-	// Check if request was successful
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	friends, err := readFriendsForReceiveMessage()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no cached friends list yet, run once without --offline")
 	}
+	return friends, err
+}
 
-	// Parse response
-	var apiResponse FriendsAPIResponse
-	err = json.Unmarshal(body, &apiResponse)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse response: %v", err)
+// recipientFromFlags returns the friend named by --to-id or --to-username in args,
+// or nil when neither flag is given. --to-username also accepts an alias.
+// The friend must be in the friends list.
+func recipientFromFlags(friends *FriendsData, args []string) (*Friend, error) {
+	if userID, ok := flagValue(args, "--to-id"); ok {
+		for i := range friends.Friends {
+			if friends.Friends[i].GetUserID() == userID {
+				return &friends.Friends[i], nil
+			}
+		}
+		return nil, fmt.Errorf("no friend with ID %q in your friends list", userID)
 	}
 
-	// Convert to FriendsData format for compatibility
-	friendsData := &FriendsData{
-		Friends: apiResponse.Friends,
+	if username, ok := flagValue(args, "--to-username"); ok {
+		return friendByName(friends, username)
 	}
 
-	return friendsData, nil
+	return nil, nil
+}
+
+// friendByName returns the friend with the given alias or username, ignoring case
+func friendByName(friends *FriendsData, name string) (*Friend, error) {
+	if friend := friendByAlias(friends, name); friend != nil {
+		return friend, nil
+	}
+	for i := range friends.Friends {
+		if strings.EqualFold(friends.Friends[i].GetUsername(), name) {
+			return &friends.Friends[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no friend named %q in your friends list", name)
 }
 
 // selectFriend displays the friends list and asks user to select one
 func selectFriend(friends *FriendsData) (*Friend, error) {
-	fmt.Println("\n--- Your Friends ---")
+	return selectFriendWithPrompt(friends, "Enter the number of the friend you want to send the message to: ")
+}
+
+// selectFriendWithPrompt displays the friends list and asks user to select one using the given prompt
+func selectFriendWithPrompt(friends *FriendsData, prompt string) (*Friend, error) {
+	promptf("\n--- Your Friends ---\n")
 	for i, friend := range friends.Friends {
 		username := friend.GetUsername()
 		userID := friend.GetUserID()
-		friendshipDate := friend.FriendshipDate
+		friendshipDate := friend.Added()
 		if friendshipDate == "" {
 			friendshipDate = "Unknown"
 		}
-		fmt.Printf("%d. %s (ID: %s) - Added: %s\n", i+1, username, userID, friendshipDate)
+		promptf("%d. %s (ID: %s) - Added: %s%s\n", i+1, username, userID, friendshipDate, unsyncedNote(&friend))
 	}
 
-	fmt.Print("\nEnter the number of the friend you want to send the message to: ")
-	var choice string
-	fmt.Scanln(&choice)
+	promptf("(Type a number or the start of a username, or q to cancel)\n")
 
-	// Convert choice to integer
-	choiceNum, err := strconv.Atoi(choice)
+	// Resolve the number or username to a friend
+	selectedFriend, err := promptForFriend(friends, prompt)
 	if err != nil {
-		return nil, fmt.Errorf("invalid choice: please enter a number")
-	}
-
-	// Validate choice
-	if choiceNum < 1 || choiceNum > len(friends.Friends) {
-		return nil, fmt.Errorf("invalid choice: please select a number between 1 and %d", len(friends.Friends))
+		return nil, err
 	}
-
-	// Return selected friend (subtract 1 for 0-based indexing)
-	selectedFriend := &friends.Friends[choiceNum-1]
-	fmt.Printf("Selected: %s\n", selectedFriend.GetUsername())
+	promptf("Selected: %s\n", selectedFriend.GetUsername())
 
 	return selectedFriend, nil
 }
 
 // sendMessage sends a message using the API
-func sendMessage(token, message, recipientUID string) error {
-	// Prepare request payload
-	messageReq := MessageRequest{
-		Message:         message,
-		RecipientUserID: recipientUID,
-	}
-
-	jsonData, err := json.Marshal(messageReq)
-	if err != nil {
-		return fmt.Errorf("failed to marshal request: %v", err)
-	}
-
-	// Create HTTP request
-	req, err := http.NewRequest("POST", "https://wasalbackend-production.up.railway.app/auth/send_message", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
-	}
-
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+token)
-
-	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+func sendMessage(token string, request MessageRequest) error {
+	messageResp, err := NewAPIClient(token).SendMessageRequest(request)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %v", err)
+		rememberFailedMessage(request, err)
+		return err
 	}
-	defer resp.Body.Close()
-
-	// Read response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response: %v", err)
+	if jsonOutput {
+		return printJSON(messageResp)
 	}
-
-	// Check if request was successful
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	if messageResp == nil {
+		return nil
 	}
-
-	// Parse and display response
-	var messageResp MessageResponse
-	err = json.Unmarshal(body, &messageResp)
-	if err != nil {
-		fmt.Printf("Response: %s\n", string(body))
+	if quiet {
+		fmt.Println(messageResp.MessageID)
 		return nil
 	}
 
 	// Display formatted response
-	fmt.Printf("\n--- Message Details ---\n")
-	fmt.Printf("Message ID: %d\n", messageResp.MessageID)
-	fmt.Printf("From: %s\n", messageResp.Sender)
-	fmt.Printf("To: %s\n", messageResp.Recipient)
-	fmt.Printf("Timestamp: %s\n", messageResp.Timestamp)
-	fmt.Printf("Status: %s\n", messageResp.Message)
+	debugf("\n--- Message Details ---\n")
+	debugf("Message ID: %d\n", messageResp.MessageID)
+	debugf("From: %s\n", messageResp.Sender)
+	debugf("To: %s\n", messageResp.Recipient)
+	debugf("Timestamp: %s\n", messageResp.Timestamp)
+	debugf("Status: %s\n", messageResp.Message)
 
 	return nil
 }
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+)
+
+// interrupt holds the context API requests are currently built with and the
+// function that cancels it
+var interrupt struct {
+	sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// requestsInFlight counts the API requests currently being sent
+var requestsInFlight atomic.Int32
+
+// appContext returns the context every API request is built with. CTRL+C while a
+// request is in flight cancels it so the hung request can be aborted, and a fresh
+// context replaces it so later requests in the same session still work.
+func appContext() context.Context {
+	interrupt.Lock()
+	defer interrupt.Unlock()
+	if interrupt.ctx == nil {
+		interrupt.ctx, interrupt.cancel = context.WithCancel(context.Background())
+	}
+	return interrupt.ctx
+}
+
+// cancelOnInterrupt makes SIGINT cancel the requests in flight, so they fail and
+// the command goes through its normal error path. With nothing in flight, such
+// as at a prompt, SIGINT exits straight away after taking the terminal out of
+// raw mode. The handler stays installed, so every CTRL+C is handled this way.
+func cancelOnInterrupt() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+
+	go func() {
+		for range signals {
+			if requestsInFlight.Load() == 0 {
+				restoreTerminal()
+				os.Exit(130)
+			}
+
+			interrupt.Lock()
+			if interrupt.cancel != nil {
+				interrupt.cancel()
+			}
+			interrupt.ctx, interrupt.cancel = context.WithCancel(context.Background())
+			interrupt.Unlock()
+			fmt.Fprintln(os.Stderr, "\nCancelling request...")
+		}
+	}()
+}
+
+// restoreOnTerminate restores the terminal and exits when the process receives SIGTERM
+func restoreOnTerminate() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM)
+
+	go func() {
+		<-signals
+		restoreTerminal()
+		os.Exit(143)
+	}()
+}
@@ -4,10 +4,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"strings"
-	"syscall"
-
-	"golang.org/x/term"
+	"unicode"
 )
 
 // RegistrationResponse represents the API response structure
@@ -19,7 +18,7 @@ type RegistrationResponse struct {
 
 // ExecuteSignup handles the complete signup process
 func ExecuteSignup() error {
-	fmt.Println("=== User Registration ===")
+	infoln("=== User Registration ===")
 	
 	// Get username
 	username, err := getUsername()
@@ -33,20 +32,37 @@ func ExecuteSignup() error {
 		return fmt.Errorf("error getting password: %v", err)
 	}
 
+	// Neither the registration nor the login that follows can be faked usefully
+	if dryRun {
+		fmt.Fprintf(os.Stderr, "[dry-run] would sign up as %s at %s\n", username, apiURL("/register"))
+		if !hasFlag(os.Args[2:], "--no-login") {
+			fmt.Fprintf(os.Stderr, "[dry-run] would then log in as %s\n", username)
+		}
+		return nil
+	}
+
 	// Register user
 	err = registerUser(username, password)
 	if err != nil {
 		return fmt.Errorf("registration failed: %v", err)
 	}
 
+	// Log straight in so the new account is ready to use, unless --no-login is given
+	if hasFlag(os.Args[2:], "--no-login") {
+		return nil
+	}
+	infoln("\nLogging in to your new account...")
+	if _, err := loginWithCredentials(username, password); err != nil {
+		return fmt.Errorf("account created but login failed (run `login` to try again): %v", err)
+	}
+
 	return nil
 }
 
 // getUsername prompts for and validates username input
 func getUsername() (string, error) {
-	fmt.Print("Enter username: ")
-	var username string
-	fmt.Scanln(&username)
+	promptf("Enter username: ")
+	username, _ := readLine()
 
 	// Validate username
 	username = strings.TrimSpace(username)
@@ -59,7 +75,7 @@ func getUsername() (string, error) {
 	}
 
 	// Check for invalid characters (basic validation)
-	if strings.Contains(username, " ") {
+	if strings.ContainsFunc(username, unicode.IsSpace) {
 		return "", fmt.Errorf("username cannot contain spaces")
 	}
 
@@ -68,39 +84,30 @@ func getUsername() (string, error) {
 
 // getPassword prompts for password input (hidden input)
 func getPassword() (string, error) {
-	fmt.Print("Enter password: ")
+	promptf("Enter password: ")
 	
-	// Hide password input
-	bytePassword, err := term.ReadPassword(int(syscall.Stdin))
+	// Hide password input on a terminal; piped input is read as a plain line
+	password, err := readPassword()
 	if err != nil {
 		return "", fmt.Errorf("failed to read password: %v", err)
 	}
-	
-	fmt.Println() // Print newline after hidden input
-
-	password := string(bytePassword)
-	password = strings.TrimSpace(password)
 
 	// Validate password
-	if password == "" {
-		return "", fmt.Errorf("password cannot be empty")
+	if err := validatePassword(password); err != nil {
+		return "", err
 	}
 
-	if len(password) < 6 {
-		return "", fmt.Errorf("password must be at least 6 characters long")
+	// Scripts piping credentials give the password once, so there is nothing to confirm
+	if !stdinIsTerminal() {
+		return password, nil
 	}
 
 	// Confirm password
-	fmt.Print("Confirm password: ")
-	byteConfirmPassword, err := term.ReadPassword(int(syscall.Stdin))
+	promptf("Confirm password: ")
+	confirmPassword, err := readPassword()
 	if err != nil {
 		return "", fmt.Errorf("failed to read password confirmation: %v", err)
 	}
-	
-	fmt.Println() // Print newline after hidden input
-
-	confirmPassword := string(byteConfirmPassword)
-	confirmPassword = strings.TrimSpace(confirmPassword)
 
 	if password != confirmPassword {
 		return "", fmt.Errorf("passwords do not match")
@@ -109,13 +116,26 @@ func getPassword() (string, error) {
 	return password, nil
 }
 
+// validatePassword checks password against the rules for new passwords
+func validatePassword(password string) error {
+	if password == "" {
+		return fmt.Errorf("password cannot be empty")
+	}
+
+	if len(password) < 6 {
+		return fmt.Errorf("password must be at least 6 characters long")
+	}
+
+	return nil
+}
+
 // registerUser sends registration request to the API
 func registerUser(username, password string) error {
 	// API endpoint
-	url := "https://wasalbackend-production.up.railway.app/register"
+	url := apiURL("/register")
 
 	// Create HTTP request
-	req, err := http.NewRequest("POST", url, nil)
+	req, err := http.NewRequestWithContext(appContext(), "POST", url, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %v", err)
 	}
@@ -126,14 +146,14 @@ func registerUser(username, password string) error {
 	req.Header.Set("Content-Type", "application/json")
 
 	// Display request details (for debugging)
-	fmt.Printf("\nSending registration request...\n")
-	fmt.Printf("URL: %s\n", url)
-	fmt.Printf("Username: %s\n", username)
-	fmt.Printf("Password: %s\n", strings.Repeat("*", len(password)))
+	infof("\nSending registration request...\n")
+	debugf("URL: %s\n", url)
+	debugf("Username: %s\n", username)
+	debugf("Password: %s\n", strings.Repeat("*", len(password)))
 
 	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	client := newHTTPClient()
+	resp, err := doRequest(client, req)
 	if err != nil {
 		return fmt.Errorf("failed to send request: %v", err)
 	}
@@ -146,27 +166,33 @@ func registerUser(username, password string) error {
 	}
 
 	// Display response details
-	fmt.Printf("\n--- API Response ---\n")
-	fmt.Printf("Status Code: %d\n", resp.StatusCode)
-	fmt.Printf("Status: %s\n", resp.Status)
+	debugf("\n--- API Response ---\n")
+	debugf("Status Code: %d\n", resp.StatusCode)
+	debugf("Status: %s\n", resp.Status)
 
-	// Handle different response codes
-	switch resp.StatusCode {
-	case http.StatusOK, http.StatusCreated:
-		fmt.Printf("✅ Registration successful!\n")
-		fmt.Printf("Response: %s\n", string(body))
+	if isSuccess(resp.StatusCode) {
+		infof("✅ Registration successful!\n")
+		debugf("Response: %s\n", string(body))
 		return nil
+	}
+
+	// Failures wrap an APIError so callers can inspect the status, and a 429
+	// reports when to retry like every other request
+	apiErr := newAPIError(resp, body)
+	switch resp.StatusCode {
 	case http.StatusBadRequest:
-		fmt.Printf("❌ Bad Request: %s\n", string(body))
-		return fmt.Errorf("registration failed - bad request: %s", string(body))
+		errorf("❌ Bad Request: %s\n", string(body))
+		return fmt.Errorf("registration failed - bad request: %w", apiErr)
 	case http.StatusConflict:
-		fmt.Printf("❌ Username already exists: %s\n", string(body))
-		return fmt.Errorf("username '%s' is already taken", username)
+		errorf("❌ Username already exists: %s\n", string(body))
+		return fmt.Errorf("username '%s' is already taken: %w", username, apiErr)
+	case http.StatusTooManyRequests:
+		return apiErr
 	case http.StatusInternalServerError:
-		fmt.Printf("❌ Server Error: %s\n", string(body))
-		return fmt.Errorf("server error occurred: %s", string(body))
+		errorf("❌ Server Error: %s\n", string(body))
+		return fmt.Errorf("server error occurred: %w", apiErr)
 	default:
-		fmt.Printf("❌ Unexpected response: %s\n", string(body))
-		return fmt.Errorf("registration failed with status %d: %s", resp.StatusCode, string(body))
+		errorf("❌ Unexpected response: %s\n", string(body))
+		return apiErr
 	}
 }
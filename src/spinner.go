@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// spinnerFrames are drawn in turn while a slow operation runs
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+// spinnerInterval is how often the spinner advances a frame
+const spinnerInterval = 100 * time.Millisecond
+
+// startSpinner animates a spinner next to label until the returned stop function
+// is called. The spinner only redraws its own line with a carriage return, so it
+// looks the same whether or not the terminal is in raw mode, and stop clears
+// the line before returning. When stdout is not a terminal label is printed once.
+func startSpinner(label string) (stop func()) {
+	if jsonOutput || !term.IsTerminal(int(os.Stdout.Fd())) {
+		infoln(label)
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(spinnerInterval)
+		defer ticker.Stop()
+
+		for frame := 0; ; frame++ {
+			fmt.Printf("\r%c %s", spinnerFrames[frame%len(spinnerFrames)], label)
+			select {
+			case <-done:
+				fmt.Print("\r\033[K")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+	}
+}
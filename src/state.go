@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// encryptedFileMagic prefixes every encrypted state file
+var encryptedFileMagic = []byte("CHATENC1")
+
+const (
+	saltSize  = 16
+	nonceSize = 24
+)
+
+// configDir returns ~/.config/chat_app, the directory holding all local state
+func configDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %v", err)
+	}
+	return filepath.Join(homeDir, ".config", "chat_app"), nil
+}
+
+// statePassphrase returns the passphrase used to encrypt local state, or "" for plaintext
+func statePassphrase() string {
+	if passphrase != "" {
+		return passphrase
+	}
+	return os.Getenv("CHAT_APP_PASSPHRASE")
+}
+
+// State files are the local caches kept in the config directory: friends.json,
+// seen.json, outbox.json (messages queued while offline), last_message.json (the
+// last message that failed to send) and aliases.json. They are read and written
+// only through readStateFile and writeStateFile, which encrypt them when a
+// passphrase is set. There are no separate drafts and no conversations are cached
+// on disk. Exports, history files, the token file, config.json and trace files are
+// written directly and never encrypted.
+
+// readStateFile reads a file from the config directory, decrypting it if needed.
+// A plaintext file is read as-is even with a passphrase set, so turning encryption
+// on keeps existing state; it is encrypted the next time it is written.
+func readStateFile(name string) ([]byte, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.HasPrefix(data, encryptedFileMagic) {
+		return data, nil
+	}
+
+	pass := statePassphrase()
+	if pass == "" {
+		return nil, fmt.Errorf("%s is encrypted: set --passphrase or CHAT_APP_PASSPHRASE", name)
+	}
+	return decryptState(data, pass)
+}
+
+// writeStateFile atomically writes a file in the config directory, encrypting it when a passphrase is set
+func writeStateFile(name string, data []byte) error {
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %v", err)
+	}
+
+	if pass := statePassphrase(); pass != "" {
+		data, err = encryptState(data, pass)
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeFileAtomic(filepath.Join(dir, name), data, 0600)
+}
+
+// removeStateFile deletes a file from the config directory; a missing file is not an error
+func removeStateFile(name string) error {
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %v", name, err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temp file and renames it over path,
+// so readers never observe a partially written file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %v", err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write temp file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to close temp file: %v", err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to set file permissions: %v", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace %s: %v", filepath.Base(path), err)
+	}
+
+	return nil
+}
+
+// deriveStateKey derives a secretbox key from the passphrase with scrypt
+func deriveStateKey(pass string, salt []byte) (*[32]byte, error) {
+	derived, err := scrypt.Key([]byte(pass), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %v", err)
+	}
+
+	var key [32]byte
+	copy(key[:], derived)
+	return &key, nil
+}
+
+// encryptState seals data as magic | salt | nonce | secretbox(data)
+func encryptState(data []byte, pass string) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %v", err)
+	}
+
+	var nonce [nonceSize]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %v", err)
+	}
+
+	key, err := deriveStateKey(pass, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	out := append([]byte{}, encryptedFileMagic...)
+	out = append(out, salt...)
+	out = append(out, nonce[:]...)
+	return secretbox.Seal(out, data, &nonce, key), nil
+}
+
+// decryptState opens data produced by encryptState
+func decryptState(data []byte, pass string) ([]byte, error) {
+	data = data[len(encryptedFileMagic):]
+	if len(data) < saltSize+nonceSize+secretbox.Overhead {
+		return nil, fmt.Errorf("encrypted file is truncated")
+	}
+
+	salt := data[:saltSize]
+	var nonce [nonceSize]byte
+	copy(nonce[:], data[saltSize:saltSize+nonceSize])
+
+	key, err := deriveStateKey(pass, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, ok := secretbox.Open(nil, data[saltSize+nonceSize:], &nonce, key)
+	if !ok {
+		return nil, fmt.Errorf("failed to decrypt file: wrong passphrase or corrupted data")
+	}
+	return plain, nil
+}
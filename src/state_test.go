@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useStateDir gives the test an empty config directory and the given passphrase
+func useStateDir(t *testing.T, pass string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CHAT_APP_PASSPHRASE", "")
+	saved := passphrase
+	passphrase = pass
+	t.Cleanup(func() { passphrase = saved })
+	return filepath.Join(home, ".config", "chat_app")
+}
+
+func TestStateFilePlaintextRoundTrip(t *testing.T) {
+	dir := useStateDir(t, "")
+	data := []byte(`{"friends":[]}`)
+
+	if err := writeStateFile("friends.json", data); err != nil {
+		t.Fatal(err)
+	}
+	onDisk, err := os.ReadFile(filepath.Join(dir, "friends.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(onDisk, data) {
+		t.Errorf("file on disk = %q, want it unencrypted", onDisk)
+	}
+
+	got, err := readStateFile("friends.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("readStateFile = %q, want %q", got, data)
+	}
+}
+
+func TestStateFileEncryptedRoundTrip(t *testing.T) {
+	dir := useStateDir(t, "correct horse")
+	data := []byte(`{"message":"secret text"}`)
+
+	if err := writeStateFile("outbox.json", data); err != nil {
+		t.Fatal(err)
+	}
+	onDisk, err := os.ReadFile(filepath.Join(dir, "outbox.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(onDisk, encryptedFileMagic) || bytes.Contains(onDisk, []byte("secret text")) {
+		t.Errorf("file on disk is not encrypted: %q", onDisk)
+	}
+
+	got, err := readStateFile("outbox.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("readStateFile = %q, want %q", got, data)
+	}
+}
+
+func TestStateFileWrongPassphrase(t *testing.T) {
+	useStateDir(t, "correct horse")
+	if err := writeStateFile("seen.json", []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	passphrase = "battery staple"
+	got, err := readStateFile("seen.json")
+	if err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("readStateFile with the wrong passphrase = %q, %v; want an error", got, err)
+	}
+
+	passphrase = ""
+	if _, err := readStateFile("seen.json"); err == nil || !strings.Contains(err.Error(), "is encrypted") {
+		t.Errorf("readStateFile without a passphrase: error = %v", err)
+	}
+}
+
+func TestStateFileCorruptHeader(t *testing.T) {
+	dir := useStateDir(t, "correct horse")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"magic only", encryptedFileMagic},
+		{"truncated salt", append(append([]byte{}, encryptedFileMagic...), make([]byte, saltSize-1)...)},
+		{"truncated box", append(append([]byte{}, encryptedFileMagic...), make([]byte, saltSize+nonceSize)...)},
+		{"garbage ciphertext", append(append([]byte{}, encryptedFileMagic...), bytes.Repeat([]byte{0xAB}, 100)...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(filepath.Join(dir, "friends.json"), tt.data, 0600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := readStateFile("friends.json")
+			if err == nil {
+				t.Errorf("readStateFile = %q, want an error", got)
+			}
+		})
+	}
+}
+
+func TestStateFilePlaintextWithPassphrase(t *testing.T) {
+	dir := useStateDir(t, "correct horse")
+	data := []byte(`{"bob":"2"}`)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "aliases.json"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readStateFile("aliases.json")
+	if err != nil {
+		t.Fatalf("reading a plaintext file with a passphrase set: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("readStateFile = %q, want %q", got, data)
+	}
+
+	// Writing it back encrypts it
+	if err := writeStateFile("aliases.json", got); err != nil {
+		t.Fatal(err)
+	}
+	onDisk, err := os.ReadFile(filepath.Join(dir, "aliases.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(onDisk, encryptedFileMagic) {
+		t.Error("rewritten file is not encrypted")
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UnreadCount is the number of unread messages from one friend
+type UnreadCount struct {
+	FriendUsername string `json:"friend_username"`
+	FriendID       string `json:"friend_id"`
+	Unread         int    `json:"unread"`
+}
+
+// showStatus implements the status command: unread message counts per friend
+func showStatus() error {
+	token, err := requireToken()
+	if err != nil {
+		return err
+	}
+
+	friends, err := fetchFriendsFromAPI(token.Token)
+	if err != nil {
+		return fmt.Errorf("error fetching friends: %v", err)
+	}
+	if len(friends.Friends) == 0 {
+		fmt.Println("You have no friends yet, use `search` to add some.")
+		return nil
+	}
+
+	conversations := fetchAllConversations(token, friends)
+
+	var counts []UnreadCount
+	for i := range friends.Friends {
+		friend := &friends.Friends[i]
+		conversation, ok := conversations[friend.GetUserID()]
+		if !ok {
+			continue
+		}
+		counts = append(counts, UnreadCount{
+			FriendUsername: friend.GetUsername(),
+			FriendID:       friend.GetUserID(),
+			Unread:         countUnread(token, friend, conversation),
+		})
+	}
+
+	// Most unread first, then alphabetically
+	sort.SliceStable(counts, func(i, j int) bool {
+		if counts[i].Unread != counts[j].Unread {
+			return counts[i].Unread > counts[j].Unread
+		}
+		return strings.ToLower(counts[i].FriendUsername) < strings.ToLower(counts[j].FriendUsername)
+	})
+
+	if jsonOutput {
+		return printJSON(counts)
+	}
+	displayUnreadCounts(counts)
+	return nil
+}
+
+// countUnread counts the messages from friend that you have not read yet
+func countUnread(token *TokenData, friend *Friend, conversation *ConversationResponse) int {
+	unread := 0
+	for _, msg := range filterConversation(token, friend, conversation) {
+		if msg.Sender != token.UserID && !msg.IsRead {
+			unread++
+		}
+	}
+	return unread
+}
+
+// displayUnreadCounts prints the unread counts as a table with a grand total
+func displayUnreadCounts(counts []UnreadCount) {
+	fmt.Println("\n=== Unread Messages ===")
+	fmt.Printf("%-24s %s\n", "Friend", "Unread")
+	fmt.Println(strings.Repeat("-", 32))
+
+	total := 0
+	for _, count := range counts {
+		fmt.Printf("%-24s %d\n", count.FriendUsername, count.Unread)
+		total += count.Unread
+	}
+
+	fmt.Println(strings.Repeat("-", 32))
+	fmt.Printf("%-24s %d\n", "Total", total)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+// rawMode remembers the state to return to while the terminal is in raw mode,
+// so a signal handler can restore it before the process exits
+var rawMode struct {
+	sync.Mutex
+	fd    int
+	state *term.State
+}
+
+// makeRaw puts the terminal into raw mode so single key presses such as
+// CTRL+R (18), CTRL+S (19) and CTRL+C (3) can be read byte by byte.
+func makeRaw(fd int) (*term.State, error) {
+	state, err := term.MakeRaw(fd)
+	if err == nil {
+		rawMode.Lock()
+		rawMode.fd, rawMode.state = fd, state
+		rawMode.Unlock()
+	}
+	return state, err
+}
+
+// restore returns the terminal to a state saved by makeRaw
+func restore(fd int, state *term.State) error {
+	rawMode.Lock()
+	rawMode.state = nil
+	rawMode.Unlock()
+	return term.Restore(fd, state)
+}
+
+// restoreTerminal leaves raw mode if it is active. It is called before the
+// process exits on a signal, so the shell is not left without echo.
+func restoreTerminal() {
+	rawMode.Lock()
+	defer rawMode.Unlock()
+	if rawMode.state != nil {
+		term.Restore(rawMode.fd, rawMode.state)
+		rawMode.state = nil
+	}
+}
+
+// dispatchKeys reads key presses from r one byte at a time and calls the handler
+// bound to each key, ignoring keys without one. It returns nil once a handler
+// returns false, or an error when r cannot be read. Taking r and the handlers as
+// parameters keeps the key loop independent of the real terminal.
+func dispatchKeys(r io.Reader, handlers map[byte]func() bool) error {
+	buffer := make([]byte, 1)
+	for {
+		n, err := r.Read(buffer)
+		if err != nil {
+			return fmt.Errorf("error reading input: %v", err)
+		}
+		if n == 0 {
+			continue
+		}
+		if handler, ok := handlers[buffer[0]]; ok && !handler() {
+			return nil
+		}
+	}
+}
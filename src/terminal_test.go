@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestDispatchKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []byte
+		want    []string
+		wantErr bool
+	}{
+		{"refresh, send, then CTRL+C", []byte{18, 19, 3}, []string{"refresh", "send", "quit"}, false},
+		{"unbound keys are ignored", []byte{'x', 18, 'y', 3}, []string{"refresh", "quit"}, false},
+		{"keys after CTRL+C are not read", []byte{3, 18}, []string{"quit"}, false},
+		{"input ends before CTRL+C", []byte{18, 19}, []string{"refresh", "send"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			record := func(key string, keepGoing bool) func() bool {
+				return func() bool {
+					got = append(got, key)
+					return keepGoing
+				}
+			}
+
+			err := dispatchKeys(bytes.NewReader(tt.input), map[byte]func() bool{
+				18: record("refresh", true),
+				19: record("send", true),
+				3:  record("quit", false),
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("dispatchKeys error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("handlers called %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
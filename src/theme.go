@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// Themes for the indicators in front of conversation messages, set with "theme" in config.json
+const (
+	themeEmoji = "emoji"
+	themeASCII = "ascii"
+	themePlain = "plain"
+)
+
+// messageTheme holds the indicators shown before sent and received messages
+type messageTheme struct {
+	sent     string
+	received string
+}
+
+var messageThemes = map[string]messageTheme{
+	themeEmoji: {sent: "📤 ", received: "📥 "},
+	themeASCII: {sent: ">> ", received: "<< "},
+	themePlain: {},
+}
+
+// currentTheme returns the theme from config.json, or when none is set the emoji
+// theme on a UTF-8 locale and the ascii theme otherwise, where emoji would be garbled.
+// It is worked out once, so an invalid theme is only warned about once.
+var currentTheme = sync.OnceValue(func() messageTheme {
+	name := loadConfig().Theme
+	if name == "" {
+		name = themeASCII
+		if localeIsUTF8() {
+			name = themeEmoji
+		}
+	}
+
+	theme, ok := messageThemes[name]
+	if !ok {
+		errorf("Warning: ignoring unknown theme %q in config file, using %s\n", name, themeASCII)
+		return messageThemes[themeASCII]
+	}
+	return theme
+})
+
+// localeIsUTF8 reports whether the locale, taken from the first of LC_ALL,
+// LC_CTYPE and LANG that is set, uses UTF-8
+func localeIsUTF8() bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			value = strings.ToLower(value)
+			return strings.Contains(value, "utf-8") || strings.Contains(value, "utf8")
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+var (
+	// ErrNoToken means no token has been saved yet (the user has not logged in)
+	ErrNoToken = errors.New("no saved token")
+	// ErrBadToken means token.json exists but cannot be parsed
+	ErrBadToken = errors.New("saved token is malformed")
+	// errNotSetUp is reported by commands that need a login when no token has been saved yet
+	errNotSetUp = errors.New("it looks like you're not set up yet — run `signup` or `login` first")
+)
+
+// tokenFilePath returns the path of the active profile's token file:
+// ~/.config/chat_app/token.json for the default profile and
+// token.<profile>.json, or the profile's token_file, for the others
+func tokenFilePath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+
+	settings, err := profileConfig()
+	if err != nil {
+		return "", err
+	}
+
+	switch name := activeProfile(); {
+	case settings.TokenFile != "" && filepath.IsAbs(settings.TokenFile):
+		return settings.TokenFile, nil
+	case settings.TokenFile != "":
+		return filepath.Join(dir, settings.TokenFile), nil
+	case name == defaultProfile:
+		return filepath.Join(dir, "token.json"), nil
+	default:
+		return filepath.Join(dir, "token."+name+".json"), nil
+	}
+}
+
+// statTokenFile returns file info for token.json
+func statTokenFile() (os.FileInfo, error) {
+	path, err := tokenFilePath()
+	if err != nil {
+		return nil, err
+	}
+	return os.Stat(path)
+}
+
+// LoadToken reads the saved token of the active profile from the system keyring
+// when enabled, otherwise from its token file (see tokenFilePath).
+// It returns an error wrapping ErrNoToken when no token has been saved
+// and ErrBadToken when its contents cannot be parsed.
+func LoadToken() (*TokenData, error) {
+	path, err := tokenFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := readTokenData(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s not found", ErrNoToken, path)
+		}
+		return nil, fmt.Errorf("failed to read token file: %v", err)
+	}
+
+	var tokenData TokenData
+	if err := json.Unmarshal(data, &tokenData); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadToken, err)
+	}
+
+	return checkTokenBaseURL(&tokenData), nil
+}
+
+// requireToken loads the saved token for a command that needs a logged-in user.
+// On a fresh install, with no token or config directory yet, it returns errNotSetUp
+// instead of the missing file error.
+func requireToken() (*TokenData, error) {
+	token, err := LoadToken()
+	if errors.Is(err, ErrNoToken) {
+		return nil, errNotSetUp
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading token: %v", err)
+	}
+	return token, nil
+}
+
+// readTokenData returns the raw saved token, from the keyring when it is enabled
+// and holds one, otherwise from the token file at path
+func readTokenData(path string) ([]byte, error) {
+	if useKeyring() {
+		if data, ok := readKeyringToken(); ok {
+			return data, nil
+		}
+	}
+	secureTokenFile(path)
+	return os.ReadFile(path)
+}
+
+// secureTokenFile restricts the token file at path to its owner (0600), and the
+// config directory holding it to 0700, when an older version or the user left them
+// readable by others. Windows does not use these permission bits, so it is skipped there.
+func secureTokenFile(path string) {
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		if err := os.Chmod(path, 0600); err != nil {
+			errorf("Warning: %s is readable by other users and could not be restricted: %v\n", path, err)
+		} else {
+			errorf("Warning: %s was readable by other users; its permissions are now 0600\n", path)
+		}
+	}
+
+	// Only the app's own directory is tightened, never a custom token_file's parent
+	dir, err := configDir()
+	if err != nil || filepath.Dir(path) != dir {
+		return
+	}
+	if info, err := os.Stat(dir); err == nil && info.Mode().Perm()&0077 != 0 {
+		if err := os.Chmod(dir, 0700); err != nil {
+			debugf("Could not restrict %s to 0700: %v\n", dir, err)
+		}
+	}
+}
+
+// checkTokenBaseURL warns on stderr when the token was issued by a different backend
+// than the one this build talks to. When stdin is a terminal and neither --json nor
+// --quiet is set it offers to log in again; scripts just get the warning. It returns
+// the token to use.
+func checkTokenBaseURL(token *TokenData) *TokenData {
+	current := apiBaseURL()
+	if token.IssuedFor == "" || token.IssuedFor == current {
+		return token
+	}
+
+	errorf("⚠️  Your token was issued by %s, but this build uses %s.\n", token.IssuedFor, current)
+	if !stdinIsTerminal() || jsonOutput || quiet {
+		return token
+	}
+
+	fmt.Print("Log in again against the current backend? [y/N]: ")
+	reader := stdinReader
+	input, _ := reader.ReadString('\n')
+	input = strings.ToLower(strings.TrimSpace(input))
+	if input != "y" && input != "yes" {
+		return token
+	}
+
+	username, password := promptLoginCredentials()
+	newToken, err := loginWithCredentials(username, password)
+	if err != nil {
+		errorf("Re-login failed: %v\n", err)
+		return token
+	}
+	return newToken
+}
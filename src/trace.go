@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HARLog is the top-level structure of the trace file (a subset of the HAR 1.2 format)
+type HARLog struct {
+	Log struct {
+		Version string     `json:"version"`
+		Creator HARCreator `json:"creator"`
+		Entries []HAREntry `json:"entries"`
+	} `json:"log"`
+}
+
+// HARCreator identifies the tool that produced the trace
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HAREntry represents a single request/response exchange
+type HAREntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Error           string      `json:"_error,omitempty"`
+}
+
+// HARRequest represents the request half of an entry
+type HARRequest struct {
+	Method   string       `json:"method"`
+	URL      string       `json:"url"`
+	Headers  []HARHeader  `json:"headers"`
+	PostData *HARPostData `json:"postData,omitempty"`
+}
+
+// HARResponse represents the response half of an entry
+type HARResponse struct {
+	Status     int         `json:"status"`
+	StatusText string      `json:"statusText"`
+	Headers    []HARHeader `json:"headers"`
+	Content    HARContent  `json:"content"`
+}
+
+// HARHeader represents a single header name/value pair
+type HARHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARPostData represents a request body
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// HARContent represents a response body
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+const redacted = "[REDACTED]"
+
+// sensitiveFields are header names and JSON keys whose values never reach the trace
+var sensitiveFields = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"set-cookie":          true,
+}
+
+// sensitivePatterns redact any header name or JSON key containing one of them,
+// such as "new_password", "X-Api-Key" or "refresh_token"
+var sensitivePatterns = []string{"password", "token", "secret", "key"}
+
+// isSensitive reports whether the value of the header or JSON key name must be redacted
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	if sensitiveFields[name] {
+		return true
+	}
+	for _, pattern := range sensitivePatterns {
+		if strings.Contains(name, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// isExtraHeader reports whether name is one of the extra_headers or --header
+// headers. Their values are often credentials for a proxy, so they are always redacted.
+func isExtraHeader(name string) bool {
+	for _, headers := range []map[string]string{loadConfig().ExtraHeaders, extraHeaders} {
+		for key := range headers {
+			if strings.EqualFold(key, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var (
+	traceMu      sync.Mutex
+	traceEntries []HAREntry
+)
+
+// doTracedRequest sends req and appends the exchange to the trace file
+func doTracedRequest(client *http.Client, req *http.Request) (*http.Response, error) {
+	entry := HAREntry{
+		Request: HARRequest{
+			Method:  req.Method,
+			URL:     req.URL.String(),
+			Headers: traceHeaders(req.Header),
+		},
+	}
+
+	// Capture the request body and put it back for the real request
+	if req.Body != nil {
+		reqBody, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %v", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+		entry.Request.PostData = &HARPostData{
+			MimeType: req.Header.Get("Content-Type"),
+			Text:     redactBody(reqBody),
+		}
+	}
+
+	start := time.Now()
+	entry.StartedDateTime = start.Format(time.RFC3339Nano)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		entry.Time = msSince(start)
+		entry.Error = err.Error()
+		recordTraceEntry(entry)
+		return nil, err
+	}
+
+	// Capture the response body and hand the caller a fresh reader
+	respBody, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	entry.Time = msSince(start)
+	entry.Response = HARResponse{
+		Status:     resp.StatusCode,
+		StatusText: http.StatusText(resp.StatusCode),
+		Headers:    traceHeaders(resp.Header),
+		Content: HARContent{
+			Size:     len(respBody),
+			MimeType: resp.Header.Get("Content-Type"),
+			Text:     redactBody(respBody),
+		},
+	}
+	if readErr != nil {
+		entry.Error = readErr.Error()
+	}
+	recordTraceEntry(entry)
+
+	return resp, nil
+}
+
+// recordTraceEntry appends an entry and rewrites the trace file.
+// The file is rewritten on every entry so it stays valid even if the process exits early.
+func recordTraceEntry(entry HAREntry) {
+	traceMu.Lock()
+	defer traceMu.Unlock()
+
+	traceEntries = append(traceEntries, entry)
+
+	var harLog HARLog
+	harLog.Log.Version = "1.2"
+	harLog.Log.Creator = HARCreator{Name: "chat_app_cli", Version: "1.0"}
+	harLog.Log.Entries = traceEntries
+
+	jsonData, err := json.MarshalIndent(harLog, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to encode trace: %v\n", err)
+		return
+	}
+	if err := os.WriteFile(traceFile, jsonData, 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write trace file: %v\n", err)
+	}
+}
+
+// traceHeaders converts headers to HAR form with sensitive values redacted
+func traceHeaders(header http.Header) []HARHeader {
+	var headers []HARHeader
+	for name, values := range header {
+		for _, value := range values {
+			if isSensitive(name) || isExtraHeader(name) {
+				value = redacted
+			}
+			headers = append(headers, HARHeader{Name: name, Value: value})
+		}
+	}
+	sort.Slice(headers, func(i, j int) bool { return headers[i].Name < headers[j].Name })
+	return headers
+}
+
+// redactBody masks sensitive keys at any depth of a JSON body; other bodies are returned as-is
+func redactBody(body []byte) string {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return string(body)
+	}
+
+	if !redactValue(value) {
+		return string(body)
+	}
+
+	redactedBody, err := json.Marshal(value)
+	if err != nil {
+		return redacted
+	}
+	return string(redactedBody)
+}
+
+// redactValue masks sensitive keys in the decoded JSON value and the objects and
+// arrays nested in it, reporting whether anything was masked
+func redactValue(value interface{}) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if isSensitive(key) {
+				v[key] = redacted
+				changed = true
+			} else if redactValue(field) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if redactValue(item) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// msSince returns the elapsed time since start in milliseconds
+func msSince(start time.Time) float64 {
+	return float64(time.Since(start).Microseconds()) / 1000
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"not JSON", "plain text", "plain text"},
+		{"nothing sensitive", `{"message":"hi"}`, `{"message":"hi"}`},
+		{"top-level password", `{"username":"me","password":"hunter2"}`, `{"password":"[REDACTED]","username":"me"}`},
+		{"key patterns", `{"new_password":"a","api_key":"b","refresh_token":"c","client_secret":"d"}`,
+			`{"api_key":"[REDACTED]","client_secret":"[REDACTED]","new_password":"[REDACTED]","refresh_token":"[REDACTED]"}`},
+		{"nested object", `{"user":{"name":"me","auth":{"Token":"abc"}}}`, `{"user":{"auth":{"Token":"[REDACTED]"},"name":"me"}}`},
+		{"objects in arrays", `{"sessions":[{"id":1,"token":"a"},{"id":2,"token":"b"}]}`,
+			`{"sessions":[{"id":1,"token":"[REDACTED]"},{"id":2,"token":"[REDACTED]"}]}`},
+		{"top-level array", `[{"password":"x"}]`, `[{"password":"[REDACTED]"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redactBody([]byte(tt.body))
+			if !jsonEqual(got, tt.want) {
+				t.Errorf("redactBody() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTraceHeadersRedactsSensitive(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer abc")
+	header.Set("Cookie", "session=abc")
+	header.Set("X-Api-Key", "abc")
+	header.Set("X-Auth-Token", "abc")
+	header.Set("Content-Type", "application/json")
+
+	for _, h := range traceHeaders(header) {
+		wantRedacted := h.Name != "Content-Type"
+		if (h.Value == redacted) != wantRedacted {
+			t.Errorf("header %s = %q, redacted should be %v", h.Name, h.Value, wantRedacted)
+		}
+	}
+}
+
+// jsonEqual reports whether two strings hold the same JSON value, or are equal when not JSON
+func jsonEqual(a, b string) bool {
+	var va, vb interface{}
+	if json.Unmarshal([]byte(a), &va) != nil || json.Unmarshal([]byte(b), &vb) != nil {
+		return a == b
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+func TestTraceHeadersRedactsExtraHeaders(t *testing.T) {
+	extraHeaders["X-Tenant"] = "acme-internal"
+	t.Cleanup(func() { delete(extraHeaders, "X-Tenant") })
+
+	header := http.Header{}
+	header.Set("X-Tenant", "acme-internal")
+	header.Set("Accept", "application/json")
+
+	for _, h := range traceHeaders(header) {
+		wantRedacted := h.Name == "X-Tenant"
+		if (h.Value == redacted) != wantRedacted {
+			t.Errorf("header %s = %q, redacted should be %v", h.Name, h.Value, wantRedacted)
+		}
+	}
+}
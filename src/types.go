@@ -6,6 +6,7 @@ type TokenData struct {
 	ExpiresIn string `json:"expires_in"`
 	UserID    string `json:"user_id"`
 	Username  string `json:"username"`
+	IssuedFor string `json:"issued_for,omitempty"`
 }
 
 // APIResponse represents the API response structure
@@ -30,6 +31,10 @@ type Friend struct {
 	// Legacy/alternative fields for backward compatibility
 	UserID   string `json:"user_id,omitempty"`
 	Username string `json:"username,omitempty"`
+	AddedAt  string `json:"added_at,omitempty"`
+
+	// Unsynced marks a friend added locally that the server does not list yet
+	Unsynced bool `json:"unsynced,omitempty"`
 }
 
 // GetUserID returns the appropriate user ID field
@@ -48,6 +53,32 @@ func (f *Friend) GetUsername() string {
 	return f.Username
 }
 
+// Added returns when the friendship was created, from whichever field is present
+func (f *Friend) Added() string {
+	if f.FriendshipDate != "" {
+		return f.FriendshipDate
+	}
+	return f.AddedAt
+}
+
+// NormalizeFriend fills the canonical API fields from the legacy cache fields and
+// back, so a friend behaves the same regardless of where it was loaded from and
+// a cached friends.json can be read by either struct shape
+func NormalizeFriend(f *Friend) {
+	if f.FriendID == "" {
+		f.FriendID = f.UserID
+	}
+	if f.FriendUsername == "" {
+		f.FriendUsername = f.Username
+	}
+	if f.FriendshipDate == "" {
+		f.FriendshipDate = f.AddedAt
+	}
+	f.UserID = f.FriendID
+	f.Username = f.FriendUsername
+	f.AddedAt = f.FriendshipDate
+}
+
 // FriendsAPIResponse represents the API response for get_friends endpoint
 type FriendsAPIResponse struct {
 	Friends      []Friend `json:"friends"`
@@ -80,6 +111,8 @@ type LoginResponse struct {
 type MessageRequest struct {
 	Message         string `json:"message"`
 	RecipientUserID string `json:"recipient_user_id"`
+	// Attachment is the URL of a file uploaded with the message, if any
+	Attachment string `json:"attachment,omitempty"`
 }
 
 // MessageResponse represents the API response
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNormalizeFriendMixedShapes(t *testing.T) {
+	data := []byte(`{"friends": [
+		{"friend_id": "2", "friend_username": "alice", "friendship_date": "2026-01-01", "friendship_id": 5},
+		{"user_id": "3", "username": "bob", "added_at": "2026-02-02"},
+		{"friend_id": "4", "username": "carol"}
+	]}`)
+
+	var friends FriendsData
+	if err := json.Unmarshal(data, &friends); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct{ id, username, added string }{
+		{"2", "alice", "2026-01-01"},
+		{"3", "bob", "2026-02-02"},
+		{"4", "carol", ""},
+	}
+	for i := range friends.Friends {
+		friend := &friends.Friends[i]
+		NormalizeFriend(friend)
+
+		if friend.FriendID != want[i].id || friend.UserID != want[i].id {
+			t.Errorf("friend %d IDs = %q/%q, want %q", i, friend.FriendID, friend.UserID, want[i].id)
+		}
+		if friend.FriendUsername != want[i].username || friend.Username != want[i].username {
+			t.Errorf("friend %d usernames = %q/%q, want %q", i, friend.FriendUsername, friend.Username, want[i].username)
+		}
+		if friend.Added() != want[i].added {
+			t.Errorf("friend %d Added() = %q, want %q", i, friend.Added(), want[i].added)
+		}
+	}
+}
+
+func TestReadFriendsMixedCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cache := []byte(`{"friends": [
+		{"friend_id": "2", "friend_username": "alice"},
+		{"user_id": "3", "username": "bob"}
+	]}`)
+	if err := writeStateFile("friends.json", cache); err != nil {
+		t.Fatal(err)
+	}
+
+	friends, err := readFriendsForReceiveMessage()
+	if err != nil {
+		t.Fatalf("readFriendsForReceiveMessage: %v", err)
+	}
+	if len(friends.Friends) != 2 {
+		t.Fatalf("read %d friends, want 2", len(friends.Friends))
+	}
+	for i, want := range []struct{ id, username string }{{"2", "alice"}, {"3", "bob"}} {
+		if friend := friends.Friends[i]; friend.FriendID != want.id || friend.FriendUsername != want.username {
+			t.Errorf("friend %d = %q/%q, want %q/%q", i, friend.FriendID, friend.FriendUsername, want.id, want.username)
+		}
+	}
+}
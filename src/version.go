@@ -0,0 +1,23 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Build information, set at build time with for example:
+//
+//	go build -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse --short HEAD) -X main.buildDate=$(date -u +%Y-%m-%d)"
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildDate = "unknown"
+)
+
+// printVersion implements the version command
+func printVersion() {
+	fmt.Printf("chat_app %s\n", version)
+	fmt.Printf("Commit:     %s\n", commit)
+	fmt.Printf("Built:      %s\n", buildDate)
+	fmt.Printf("Go version: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// watchConversation prints the latest page of the conversation with friend and then,
+// like tail -f, only the messages that arrive after it, checking every pollInterval
+// (defaultPollInterval when unset) and backing off while nothing arrives. There are no key bindings; it runs until CTRL+C.
+// Messages are not marked as read, so the feed can be left running unattended.
+func watchConversation(token *TokenData, friend *Friend) error {
+	interval := pollInterval
+	if interval == 0 {
+		interval = defaultPollInterval
+	}
+	friendUsername := friendDisplayName(friend)
+
+	conversation, err := getConversation(token, friend)
+	if err != nil {
+		return fmt.Errorf("error fetching conversation: %v", err)
+	}
+
+	// Remember every message already fetched so only new ones are printed later
+	shown := make(map[int]bool)
+	messages := filterConversation(token, friend, conversation)
+	for _, msg := range messages {
+		shown[msg.MessageID] = true
+	}
+	start, end := pageBounds(os.Stdout, len(messages))
+	for _, msg := range messages[start:end] {
+		printMessage(os.Stdout, token, friendUsername, msg)
+	}
+	fmt.Fprintf(os.Stderr, "Watching conversation with %s every %s (CTRL+C to stop)...\n", friendUsername, interval)
+
+	backoff := newPollBackoff(interval)
+
+	for {
+		time.Sleep(backoff.next())
+
+		// Transient failures are reported and the next tick tries again
+		conversation, err := getConversation(token, friend)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "Warning: could not fetch conversation: %v\n", err)
+			backoff.update(false)
+			continue
+		}
+
+		foundNew := false
+		for _, msg := range filterConversation(token, friend, conversation) {
+			if shown[msg.MessageID] {
+				continue
+			}
+			shown[msg.MessageID] = true
+			foundNew = true
+			printMessage(os.Stdout, token, friendUsername, msg)
+		}
+		backoff.update(foundNew)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// whoami prints the identity stored in token.json
+func whoami() error {
+	token, err := LoadToken()
+	switch {
+	case errors.Is(err, ErrNoToken):
+		return errors.New("not logged in")
+	case errors.Is(err, ErrBadToken):
+		return fmt.Errorf("the saved token is corrupt, run `login` again: %w", err)
+	case err != nil:
+		return err
+	}
+
+	fmt.Println("=== Current Identity ===")
+	fmt.Printf("Username:   %s\n", token.Username)
+	fmt.Printf("User ID:    %s\n", token.UserID)
+	fmt.Printf("Expires in: %s\n", token.ExpiresIn)
+	fmt.Printf("Profile:    %s (%s)\n", activeProfile(), apiBaseURL())
+
+	// The token file's modification time is when the token was saved at login
+	if info, err := statTokenFile(); err == nil {
+		if expiry, ok := tokenExpiry(token.ExpiresIn, info.ModTime()); ok {
+			remaining := time.Until(expiry)
+			if remaining > 0 {
+				fmt.Printf("Time left:  %s (until %s)\n", remaining.Round(time.Minute), expiry.Format("Jan 2, 2006 at 3:04 PM"))
+			} else {
+				fmt.Printf("Time left:  expired %s ago, run `login` again\n", (-remaining).Round(time.Minute))
+			}
+		}
+	}
+
+	return nil
+}
+
+// tokenExpiry works out when a token expires from its ExpiresIn value, which may be
+// an absolute timestamp, a Go duration ("24h"), or a number of seconds relative to issuedAt
+func tokenExpiry(expiresIn string, issuedAt time.Time) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, time.RFC1123, "2006-01-02 15:04:05"} {
+		if expiry, err := time.Parse(layout, expiresIn); err == nil {
+			return expiry, true
+		}
+	}
+	if duration, err := time.ParseDuration(expiresIn); err == nil {
+		return issuedAt.Add(duration), true
+	}
+	if seconds, err := strconv.Atoi(expiresIn); err == nil {
+		return issuedAt.Add(time.Duration(seconds) * time.Second), true
+	}
+	return time.Time{}, false
+}
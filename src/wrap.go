@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// wrapIndent is the indentation used for wrapped lines, matching the status lines
+const wrapIndent = "   "
+
+// terminalWidth returns the width of stdout, or 0 when stdout is not a terminal
+func terminalWidth() int {
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		return 0
+	}
+	width, _, err := term.GetSize(fd)
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// wrapMessage joins prefix and text, word-wrapping text to the terminal width.
+// Continuation lines are indented with wrapIndent. Wrapping is skipped when
+// stdout is not a terminal.
+func wrapMessage(prefix, text string) string {
+	width := terminalWidth()
+	if width == 0 {
+		return prefix + text
+	}
+
+	firstWidth := width - visibleLen(prefix)
+	restWidth := width - len(wrapIndent)
+	lines := wrapText(text, firstWidth, restWidth)
+	return prefix + strings.Join(lines, "\n"+wrapIndent)
+}
+
+// wrapText splits text into lines no wider than firstWidth for the first line
+// and width for the rest. Words longer than a line are broken.
+func wrapText(text string, firstWidth, width int) []string {
+	// Keep a sane minimum so narrow terminals still make progress
+	firstWidth = max(firstWidth, 10)
+	width = max(width, 10)
+
+	var lines []string
+	limit := firstWidth
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			// Break words that cannot fit on any line
+			for utf8.RuneCountInString(word) > limit {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+					limit = width
+				}
+				runes := []rune(word)
+				lines = append(lines, string(runes[:limit]))
+				word = string(runes[limit:])
+				limit = width
+			}
+
+			switch {
+			case line == "":
+				line = word
+			case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= limit:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+				limit = width
+			}
+		}
+		lines = append(lines, line)
+		limit = width
+	}
+
+	return lines
+}
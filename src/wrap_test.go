@@ -0,0 +1,65 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		name              string
+		text              string
+		firstWidth, width int
+		want              []string
+	}{
+		{"fits on one line", "hello world", 20, 20, []string{"hello world"}},
+		{"wraps at word boundaries", "the quick brown fox jumps over", 15, 12, []string{"the quick brown", "fox jumps", "over"}},
+		{"breaks words wider than the line", strings.Repeat("x", 25), 10, 10, []string{"xxxxxxxxxx", "xxxxxxxxxx", "xxxxx"}},
+		{"keeps paragraphs", "one\ntwo", 20, 20, []string{"one", "two"}},
+		{"narrow terminal uses the minimum width", "aaaaa bbbbb ccccc", 2, 2, []string{"aaaaa", "bbbbb", "ccccc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapText(tt.text, tt.firstWidth, tt.width); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wrapText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrapTextWiderThanTerminal(t *testing.T) {
+	text := strings.Repeat("lorem ipsum dolor sit amet ", 20) + strings.Repeat("z", 100)
+	for i, line := range wrapText(text, 30, 37) {
+		limit := 37
+		if i == 0 {
+			limit = 30
+		}
+		if n := utf8.RuneCountInString(line); n > limit {
+			t.Errorf("line %d is %d characters wide, over %d: %q", i, n, limit, line)
+		}
+	}
+}
+
+func TestVisibleLen(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"You: ", 5},
+		{colorize(ansiCyan, "You: ") + ansiReset, 5},
+		{"📤 [10:00] You: ", 16},
+		{"-> [10:00] You: ", 16},
+		{"↩️ ", 2},
+		{"日本", 4},
+		{"café", 4},
+	}
+
+	for _, tt := range tests {
+		if got := visibleLen(tt.s); got != tt.want {
+			t.Errorf("visibleLen(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}